@@ -2,7 +2,10 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"testing"
 
@@ -88,3 +91,56 @@ func cliTest(testName string) func(t *testing.T) {
 		}
 	}
 }
+
+func TestFunctionsCommand(t *testing.T) {
+	for _, outputFormat := range []string{"table", "json"} {
+		t.Run(outputFormat, func(t *testing.T) {
+			// Capture the real stdout rather than calling SetOut to verify what a user piping the output would get
+			stdoutReader, stdoutWriter, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			savedStdout := os.Stdout
+			os.Stdout = stdoutWriter
+
+			defer func() { os.Stdout = savedStdout }()
+
+			cmd := (&utils.TemplateResolver{}).GetCmd()
+			cmd.SetArgs([]string{"functions", "--output", outputFormat})
+
+			cmdErr := cmd.Execute()
+
+			os.Stdout = savedStdout
+
+			stdoutWriter.Close()
+
+			stdout, err := io.ReadAll(stdoutReader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cmdErr != nil {
+				t.Fatal(cmdErr)
+			}
+
+			if outputFormat == "table" {
+				if !strings.HasPrefix(string(stdout), "NAME") || !strings.Contains(string(stdout), "fromConfigMap") {
+					t.Fatalf("Expected the functions table on stdout but got: %s", stdout)
+				}
+
+				return
+			}
+
+			functions := []map[string]interface{}{}
+
+			if err := json.Unmarshal(stdout, &functions); err != nil {
+				t.Fatalf("Expected the functions as JSON on stdout but got: %s", stdout)
+			}
+
+			if len(functions) == 0 {
+				t.Fatal("Expected at least one function in the JSON output")
+			}
+		})
+	}
+}
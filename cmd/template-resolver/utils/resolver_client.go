@@ -1,10 +1,14 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+
+	"github.com/stolostron/go-template-utils/v6/pkg/templates"
 )
 
 // Struct representing the template-resolver command
@@ -12,6 +16,8 @@ type TemplateResolver struct {
 	hubKubeConfigPath string
 	clusterName       string
 	hubNamespace      string
+	outputFormat      string
+	hubTemplates      bool
 }
 
 func (t *TemplateResolver) GetCmd() *cobra.Command {
@@ -48,6 +54,31 @@ func (t *TemplateResolver) GetCmd() *cobra.Command {
 		"the namespace on the hub to restrict namespaced lookups to when resolving hub templates",
 	)
 
+	// functions subcommand lists the available template functions
+	functionsCmd := &cobra.Command{
+		Use:   "functions",
+		Short: "List the available template functions",
+		Long:  "List the template functions available when resolving templates with template-resolver",
+		Args:  cobra.NoArgs,
+		RunE:  t.listFunctions,
+	}
+
+	functionsCmd.Flags().StringVarP(
+		&t.outputFormat,
+		"output",
+		"o",
+		"table",
+		"the output format, either table or json",
+	)
+	functionsCmd.Flags().BoolVar(
+		&t.hubTemplates,
+		"hub",
+		false,
+		"list the functions available in hub templates instead of managed cluster templates",
+	)
+
+	templateResolverCmd.AddCommand(functionsCmd)
+
 	return templateResolverCmd
 }
 
@@ -98,6 +129,50 @@ func (t *TemplateResolver) resolveTemplates(cmd *cobra.Command, args []string) e
 	return nil
 }
 
+func (t *TemplateResolver) listFunctions(cmd *cobra.Command, _ []string) error {
+	// Use the same configuration as the resolver used by resolveTemplates
+	config := templates.Config{}
+	if t.hubTemplates {
+		config = hubTemplateConfig()
+	}
+
+	functions := templates.AvailableFunctions(config, nil)
+
+	switch t.outputFormat {
+	case "json":
+		functionsJSON, err := json.MarshalIndent(functions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the functions to JSON: %w", err)
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), string(functionsJSON))
+	case "table":
+		writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(writer, "NAME\tSOURCE\tQUERIES API\tSENSITIVE\tSIGNATURE")
+
+		for _, function := range functions {
+			fmt.Fprintf(
+				writer,
+				"%s\t%s\t%t\t%t\t%s\n",
+				function.Name,
+				function.Source,
+				function.QueriesAPIServer,
+				function.ReturnsSensitiveData,
+				function.Signature,
+			)
+		}
+
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("failed to write the functions table: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid output format %q: must be table or json", t.outputFormat)
+	}
+
+	return nil
+}
+
 // Execute runs the `template-resolver` command.
 func Execute() error {
 	tmplResolverCmd := TemplateResolver{}
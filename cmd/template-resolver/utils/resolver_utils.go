@@ -31,6 +31,16 @@ type hubTemplateOptions struct {
 	ctx    hubTemplateCtx
 }
 
+// hubTemplateConfig returns the resolver configuration used for hub templates.
+func hubTemplateConfig() templates.Config {
+	return templates.Config{
+		AdditionalIndentation: 8,
+		DisabledFunctions:     []string{},
+		StartDelim:            "{{hub",
+		StopDelim:             "hub}}",
+	}
+}
+
 // HandleFile takes a file path and returns the resulting byte array. If an
 // empty string ("") or hyphen ("-") is provided, input is read from stdin.
 func HandleFile(yamlFile string) ([]byte, error) {
@@ -88,14 +98,7 @@ func ProcessTemplate(yamlBytes []byte, hubKubeConfigPath, clusterName, hubNS str
 		return nil, fmt.Errorf("failed to determine the kubeconfig to use: %w", err)
 	}
 
-	hubTemplateOpts := &hubTemplateOptions{
-		config: templates.Config{
-			AdditionalIndentation: 8,
-			DisabledFunctions:     []string{},
-			StartDelim:            "{{hub",
-			StopDelim:             "hub}}",
-		},
-	}
+	hubTemplateOpts := &hubTemplateOptions{config: hubTemplateConfig()}
 
 	var hubResolver *templates.TemplateResolver
 
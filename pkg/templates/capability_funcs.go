@@ -0,0 +1,102 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// parseGVK returns the GroupVersionKind of the input apiVersion and kind.
+func parseGVK(apiVersion string, kind string) (schema.GroupVersionKind, error) {
+	if apiVersion == "" || kind == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("%w: the apiVersion and kind are required", ErrInvalidInput)
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("%w: the apiVersion %s is invalid", ErrInvalidInput, apiVersion)
+	}
+
+	return gv.WithKind(kind), nil
+}
+
+// hasAPIResource returns true if the API server serves the input apiVersion and kind based on the discovery information
+// cached by the resolver. This allows a template to check if a CRD is installed before calling "lookup", which would
+// otherwise fail with ErrMissingAPIResource. In caching mode, no watch is added, so a template isn't resolved again
+// when the API resource is installed or removed later.
+func (t *TemplateResolver) hasAPIResource(apiVersion string, kind string) (bool, error) {
+	gvk, err := parseGVK(apiVersion, kind)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = t.gvkToGVR(gvk)
+	if err != nil {
+		if errors.Is(err, ErrMissingAPIResource) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// isNamespacedAPIResource returns true if the input apiVersion and kind is namespaced and false if it's cluster-scoped.
+// ErrMissingAPIResource is returned if the API server doesn't serve it.
+func (t *TemplateResolver) isNamespacedAPIResource(apiVersion string, kind string) (bool, error) {
+	gvk, err := parseGVK(apiVersion, kind)
+	if err != nil {
+		return false, err
+	}
+
+	scopedGVRObj, err := t.gvkToGVR(gvk)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s %s", err, apiVersion, kind)
+	}
+
+	return scopedGVRObj.Namespaced, nil
+}
+
+// serverVersionCacheTTL is how long the server version is cached by the resolver, so that the API server isn't queried
+// on every "serverVersion" call but cluster upgrades are still detected by long-lived resolvers.
+const serverVersionCacheTTL = 10 * time.Minute
+
+func (t *TemplateResolver) serverVersionHelper(options *ResolveOptions) func() (string, error) {
+	return func() (string, error) {
+		return t.serverVersion(options)
+	}
+}
+
+// serverVersion returns the Kubernetes version of the API server (e.g. "v1.30.4"). The result is cached by the resolver
+// for serverVersionCacheTTL according to the configured clock.
+func (t *TemplateResolver) serverVersion(options *ResolveOptions) (string, error) {
+	if t.discoveryClient == nil {
+		return "", errors.New(
+			"the server version is not available when using an external DynamicWatcher without Config.DiscoveryClient",
+		)
+	}
+
+	now := t.getClock(options).Now()
+
+	t.serverVersionLock.Lock()
+	defer t.serverVersionLock.Unlock()
+
+	if t.serverVersionCache != "" && now.Before(t.serverVersionExpires) {
+		return t.serverVersionCache, nil
+	}
+
+	versionInfo, err := t.discoveryClient.ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the server version: %w", err)
+	}
+
+	t.serverVersionCache = versionInfo.GitVersion
+	t.serverVersionExpires = now.Add(serverVersionCacheTTL)
+
+	return t.serverVersionCache, nil
+}
@@ -0,0 +1,146 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/version"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestAPIResourceFunctions(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	testcases := map[string]struct {
+		apiVersion         string
+		kind               string
+		expectedServed     bool
+		expectedNamespaced bool
+		expectedErr        error
+	}{
+		"namespaced":     {"v1", "ConfigMap", true, true, nil},
+		"cluster_scoped": {"v1", "Node", true, false, nil},
+		"missing_kind":   {"v1", "Widget", false, false, ErrMissingAPIResource},
+		"missing_group":  {"example.com/v1", "Widget", false, false, ErrMissingAPIResource},
+		"invalid":        {"example.com/v1/v2", "Widget", false, false, ErrInvalidInput},
+		"empty_kind":     {"v1", "", false, false, ErrInvalidInput},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			served, err := resolver.hasAPIResource(test.apiVersion, test.kind)
+			if test.expectedErr == ErrInvalidInput { //nolint:errorlint
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("Expected an invalid input error but got: %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if served != test.expectedServed {
+				t.Fatalf("Expected hasAPIResource to return %v but got %v", test.expectedServed, served)
+			}
+
+			namespaced, err := resolver.isNamespacedAPIResource(test.apiVersion, test.kind)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			if namespaced != test.expectedNamespaced {
+				t.Fatalf(
+					"Expected isNamespacedAPIResource to return %v but got %v", test.expectedNamespaced, namespaced,
+				)
+			}
+		})
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient := &discoveryfake.FakeDiscovery{
+		Fake:               &clienttesting.Fake{},
+		FakedServerVersion: &version.Info{GitVersion: "v1.30.4", Major: "1", Minor: "30"},
+	}
+
+	fakeClock := clocktesting.NewFakePassiveClock(time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC))
+
+	resolver, err := NewResolverWithClients(nil, discoveryClient, Config{Clock: fakeClock})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		serverVersion, err := resolver.serverVersion(&ResolveOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if serverVersion != "v1.30.4" {
+			t.Fatalf("Expected the server version v1.30.4 but got %s", serverVersion)
+		}
+	}
+
+	if len(discoveryClient.Actions()) != 1 {
+		t.Fatalf("Expected the server version to be cached but got %d requests", len(discoveryClient.Actions()))
+	}
+
+	// The cached server version expires according to the clock
+	fakeClock.SetTime(fakeClock.Now().Add(serverVersionCacheTTL))
+
+	if _, err := resolver.serverVersion(&ResolveOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(discoveryClient.Actions()) != 2 {
+		t.Fatalf("Expected the cached server version to expire but got %d requests", len(discoveryClient.Actions()))
+	}
+
+	resolver, err = NewResolverWithDynamicWatcher(nil, Config{DiscoveryClient: discoveryClient})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverVersion, err := resolver.serverVersion(&ResolveOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if serverVersion != "v1.30.4" {
+		t.Fatalf("Expected the server version v1.30.4 but got %s", serverVersion)
+	}
+
+	resolver, err = NewResolverWithDynamicWatcher(nil, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := resolver.serverVersion(&ResolveOptions{}); err == nil {
+		t.Fatal("Expected an error without a discovery client")
+	}
+}
+
+func TestAPIResourceFunctionsTemplate(t *testing.T) {
+	t.Parallel()
+
+	doResolveTest(t, resolveTestCase{
+		inputTmpl: `configmap: '{{ hasAPIResource "v1" "ConfigMap" }}'
+route: '{{ if hasAPIResource "route.openshift.io/v1" "Route" }}{{ lookup "route.openshift.io/v1" "Route" "" "" }}` +
+			`{{ else }}not installed{{ end }}'
+namespaced: '{{ isNamespacedAPIResource "v1" "Node" }}'
+version: '{{ semverCompare ">=1.20.0" serverVersion }}'`,
+		resolveOptions: ResolveOptions{InputIsYAML: true},
+		expectedResult: "configmap: \"true\"\nnamespaced: \"false\"\nroute: not installed\nversion: \"true\"",
+	})
+}
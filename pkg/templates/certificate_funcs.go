@@ -0,0 +1,182 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// parsePEMCertificates returns the certificates in the input PEM data. Since "fromSecret" returns the Base64 encoded
+// value, Base64 encoded PEM data is decoded first. Blocks other than certificates, such as private keys, are skipped.
+func parsePEMCertificates(data interface{}) ([]*x509.Certificate, error) {
+	var pemData []byte
+
+	switch typedData := data.(type) {
+	case string:
+		pemData = []byte(typedData)
+	case []byte:
+		pemData = typedData
+	default:
+		return nil, fmt.Errorf("%w: expected PEM data but got %T", ErrInvalidInput, data)
+	}
+
+	// With encryption enabled, fromSecret returns encrypted values that are only decrypted on the managed cluster
+	if strings.HasPrefix(string(pemData), protectedPrefix) {
+		return nil, fmt.Errorf(
+			"%w: the input is encrypted, use lookup to get the certificate when encryption is enabled", ErrInvalidInput,
+		)
+	}
+
+	if !strings.Contains(string(pemData), "-----BEGIN") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(pemData)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: the input is not PEM or Base64 encoded PEM data", ErrInvalidInput)
+		}
+
+		pemData = decoded
+	}
+
+	certs := []*x509.Certificate{}
+
+	for {
+		var block *pem.Block
+
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to parse the certificate: %w", ErrInvalidInput, err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%w: no certificate was found in the PEM data", ErrInvalidInput)
+	}
+
+	return certs, nil
+}
+
+// parseCertificate returns the details of the first certificate in the input PEM data, which is the leaf certificate
+// of a certificate chain such as the tls.crt key of a kubernetes.io/tls Secret. The returned map contains the subject,
+// issuer, commonName, dnsNames, ipAddresses, emailAddresses, uris, serialNumber (hexadecimal), notBefore and notAfter
+// (RFC 3339), and isCA fields.
+func parseCertificate(data interface{}) (map[string]interface{}, error) {
+	certs, err := parsePEMCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := certs[0]
+
+	ipAddresses := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+
+	uris := make([]string, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	dnsNames := cert.DNSNames
+	if dnsNames == nil {
+		dnsNames = []string{}
+	}
+
+	emailAddresses := cert.EmailAddresses
+	if emailAddresses == nil {
+		emailAddresses = []string{}
+	}
+
+	return map[string]interface{}{
+		"subject":        cert.Subject.String(),
+		"issuer":         cert.Issuer.String(),
+		"commonName":     cert.Subject.CommonName,
+		"dnsNames":       dnsNames,
+		"ipAddresses":    ipAddresses,
+		"emailAddresses": emailAddresses,
+		"uris":           uris,
+		"serialNumber":   fmt.Sprintf("%x", cert.SerialNumber),
+		"notBefore":      cert.NotBefore.UTC().Format(time.RFC3339),
+		"notAfter":       cert.NotAfter.UTC().Format(time.RFC3339),
+		"isCA":           cert.IsCA,
+	}, nil
+}
+
+func (t *TemplateResolver) certificateDaysUntilExpiryHelper(options *ResolveOptions) func(interface{}) (int, error) {
+	return func(data interface{}) (int, error) {
+		return t.certificateDaysUntilExpiry(options, data)
+	}
+}
+
+// certificateDaysUntilExpiry returns the number of whole days until the first certificate in the input PEM data
+// expires. This is negative if the certificate is expired.
+func (t *TemplateResolver) certificateDaysUntilExpiry(options *ResolveOptions, data interface{}) (int, error) {
+	certs, err := parsePEMCertificates(data)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := certs[0].NotAfter.Sub(t.getClock(options).Now())
+
+	return int(math.Floor(remaining.Hours() / 24)), nil
+}
+
+func (t *TemplateResolver) verifyCertificateHelper(
+	options *ResolveOptions,
+) func(interface{}, interface{}) (bool, error) {
+	return func(caBundle interface{}, data interface{}) (bool, error) {
+		return t.verifyCertificate(options, caBundle, data)
+	}
+}
+
+// verifyCertificate returns true if the first certificate in the input PEM data is currently valid and chains to a
+// certificate in the input CA bundle. Additional certificates in the PEM data are used as intermediate certificates.
+// An error is only returned if the input can't be parsed.
+func (t *TemplateResolver) verifyCertificate(
+	options *ResolveOptions, caBundle interface{}, data interface{},
+) (bool, error) {
+	caCerts, err := parsePEMCertificates(caBundle)
+	if err != nil {
+		return false, fmt.Errorf("invalid CA bundle: %w", err)
+	}
+
+	certs, err := parsePEMCertificates(data)
+	if err != nil {
+		return false, err
+	}
+
+	roots := x509.NewCertPool()
+	for _, caCert := range caCerts {
+		roots.AddCert(caCert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range certs[1:] {
+		intermediates.AddCert(intermediate)
+	}
+
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   t.getClock(options).Now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+
+	return err == nil, nil
+}
@@ -0,0 +1,155 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// conditionObject converts the input object, such as the result of the "lookup" template function, to an
+// Unstructured object. A nil object (e.g. a lookup of an object that doesn't exist) returns nil.
+func conditionObject(obj interface{}) (*unstructured.Unstructured, error) {
+	switch typedObj := obj.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		if len(typedObj) == 0 {
+			return nil, nil
+		}
+
+		return &unstructured.Unstructured{Object: typedObj}, nil
+	default:
+		return nil, fmt.Errorf("%w: expected a Kubernetes object but got %T", ErrInvalidInput, obj)
+	}
+}
+
+// findCondition returns the condition of the input type from the status.conditions of the input object. nil is
+// returned if the object or condition doesn't exist.
+func findCondition(conditionType string, obj interface{}) (map[string]interface{}, error) {
+	typedObj, err := conditionObject(obj)
+	if err != nil || typedObj == nil {
+		return nil, err
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(typedObj.Object, "status", "conditions")
+
+	for _, condition := range conditions {
+		typedCondition, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if typedCondition["type"] == conditionType {
+			return typedCondition, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// conditionField returns the string value of the field of the condition of the input type. An empty string is
+// returned if the condition or field doesn't exist.
+func conditionField(field string, conditionType string, obj interface{}) (string, error) {
+	condition, err := findCondition(conditionType, obj)
+	if err != nil || condition == nil {
+		return "", err
+	}
+
+	value, _ := condition[field].(string)
+
+	return value, nil
+}
+
+// conditionStatus returns the status (e.g. "True") of the condition of the input type on the input object.
+func conditionStatus(conditionType string, obj interface{}) (string, error) {
+	return conditionField("status", conditionType, obj)
+}
+
+// conditionReason returns the reason of the condition of the input type on the input object.
+func conditionReason(conditionType string, obj interface{}) (string, error) {
+	return conditionField("reason", conditionType, obj)
+}
+
+// conditionMessage returns the message of the condition of the input type on the input object.
+func conditionMessage(conditionType string, obj interface{}) (string, error) {
+	return conditionField("message", conditionType, obj)
+}
+
+// nestedNumber returns the numeric value of the input field and whether it's set. Both integers from the API server and
+// floats from JSON parsed in the template are supported.
+func nestedNumber(obj *unstructured.Unstructured, fields ...string) (float64, bool) {
+	value, found, err := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+	if err != nil || !found {
+		return 0, false
+	}
+
+	return toFloat64(value)
+}
+
+// isObservedGenerationCurrent returns true if the status.observedGeneration of the input object matches its
+// metadata.generation, which means the controller has processed the latest spec. This is false if the object doesn't
+// exist or doesn't report an observedGeneration.
+func isObservedGenerationCurrent(obj interface{}) (bool, error) {
+	typedObj, err := conditionObject(obj)
+	if err != nil || typedObj == nil {
+		return false, err
+	}
+
+	observedGeneration, found := nestedNumber(typedObj, "status", "observedGeneration")
+	if !found {
+		return false, nil
+	}
+
+	return int64(observedGeneration) >= typedObj.GetGeneration(), nil
+}
+
+// isReady returns true if the input object is ready based on its kind:
+//
+// - Deployment: the latest generation is observed, and the updated and available replicas match the desired replicas.
+//
+// - Pod: the Ready condition is True.
+//
+// - CustomResourceDefinition: the Established condition is True.
+//
+// - ClusterServiceVersion: the status.phase is Succeeded.
+//
+// Any other kind is ready if it has a Ready condition that is True. An object that doesn't exist is not ready.
+func isReady(obj interface{}) (bool, error) {
+	typedObj, err := conditionObject(obj)
+	if err != nil || typedObj == nil {
+		return false, err
+	}
+
+	switch typedObj.GetKind() {
+	case "Deployment":
+		current, err := isObservedGenerationCurrent(obj)
+		if err != nil || !current {
+			return false, err
+		}
+
+		replicas, found := nestedNumber(typedObj, "spec", "replicas")
+		if !found {
+			// The Kubernetes API server defaults this to 1
+			replicas = 1
+		}
+
+		updatedReplicas, _ := nestedNumber(typedObj, "status", "updatedReplicas")
+		availableReplicas, _ := nestedNumber(typedObj, "status", "availableReplicas")
+
+		return updatedReplicas >= replicas && availableReplicas >= replicas, nil
+	case "CustomResourceDefinition":
+		status, err := conditionStatus("Established", obj)
+
+		return status == "True", err
+	case "ClusterServiceVersion":
+		phase, _, _ := unstructured.NestedString(typedObj.Object, "status", "phase")
+
+		return phase == "Succeeded", nil
+	default:
+		status, err := conditionStatus("Ready", obj)
+
+		return status == "True", err
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConditionFunctions(t *testing.T) {
+	t.Parallel()
+
+	obj := map[string]interface{}{
+		"kind":     "MyResource",
+		"metadata": map[string]interface{}{"name": "test", "generation": int64(1)},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type": "Ready", "status": "False", "reason": "Progressing", "message": "Waiting for the rollout",
+				},
+				map[string]interface{}{"type": "Degraded", "status": "False"},
+			},
+		},
+	}
+
+	testcases := map[string]struct {
+		fn            func(string, interface{}) (string, error)
+		conditionType string
+		obj           interface{}
+		expected      string
+		expectedErr   error
+	}{
+		"status":            {conditionStatus, "Ready", obj, "False", nil},
+		"reason":            {conditionReason, "Ready", obj, "Progressing", nil},
+		"message":           {conditionMessage, "Ready", obj, "Waiting for the rollout", nil},
+		"missing_field":     {conditionReason, "Degraded", obj, "", nil},
+		"missing_condition": {conditionStatus, "Available", obj, "", nil},
+		"no_conditions":     {conditionStatus, "Ready", map[string]interface{}{"kind": "MyResource"}, "", nil},
+		"nil_object":        {conditionStatus, "Ready", nil, "", nil},
+		"empty_object":      {conditionStatus, "Ready", map[string]interface{}{}, "", nil},
+		"invalid_object":    {conditionStatus, "Ready", "not an object", "", ErrInvalidInput},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := test.fn(test.conditionType, test.obj)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			if value != test.expected {
+				t.Fatalf("Expected %q but got %q", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestReadinessFunctions(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		obj             interface{}
+		expectedReady   bool
+		expectedCurrent bool
+	}{
+		"deployment_ready": {
+			map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2), "updatedReplicas": int64(3), "availableReplicas": int64(3),
+				},
+			},
+			true,
+			true,
+		},
+		"deployment_default_ready": {
+			map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2), "updatedReplicas": int64(1), "availableReplicas": int64(1),
+				},
+			},
+			true,
+			true,
+		},
+		"deployment_json_numbers": {
+			map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": float64(2)},
+				"spec":     map[string]interface{}{"replicas": float64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": float64(2), "updatedReplicas": float64(3), "availableReplicas": float64(3),
+				},
+			},
+			true,
+			true,
+		},
+		"deployment_unavailable": {
+			map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2), "updatedReplicas": int64(3), "availableReplicas": int64(2),
+				},
+			},
+			false,
+			true,
+		},
+		"deployment_rolling_out": {
+			map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2), "updatedReplicas": int64(1), "availableReplicas": int64(3),
+				},
+			},
+			false,
+			true,
+		},
+		"deployment_old_generation": {
+			map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(3)},
+				"spec":     map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2), "updatedReplicas": int64(3), "availableReplicas": int64(3),
+				},
+			},
+			false,
+			false,
+		},
+		"pod_ready": {
+			map[string]interface{}{
+				"kind": "Pod",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+				},
+			},
+			true,
+			false,
+		},
+		"pod_not_ready": {
+			map[string]interface{}{
+				"kind": "Pod",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}},
+				},
+			},
+			false,
+			false,
+		},
+		"crd_established": {
+			map[string]interface{}{
+				"kind": "CustomResourceDefinition",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Established", "status": "True"}},
+				},
+			},
+			true,
+			false,
+		},
+		"crd_not_established": {
+			map[string]interface{}{
+				"kind": "CustomResourceDefinition",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Established", "status": "False"}},
+				},
+			},
+			false,
+			false,
+		},
+		"csv_succeeded": {
+			map[string]interface{}{
+				"kind": "ClusterServiceVersion", "status": map[string]interface{}{"phase": "Succeeded"},
+			},
+			true,
+			false,
+		},
+		"csv_installing": {
+			map[string]interface{}{
+				"kind": "ClusterServiceVersion", "status": map[string]interface{}{"phase": "Installing"},
+			},
+			false,
+			false,
+		},
+		"other_kind_ready": {
+			map[string]interface{}{
+				"kind":     "MyResource",
+				"metadata": map[string]interface{}{"generation": int64(4)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(4),
+					"conditions":         []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+				},
+			},
+			true,
+			true,
+		},
+		"other_kind_no_condition": {
+			map[string]interface{}{"kind": "MyResource", "metadata": map[string]interface{}{"generation": int64(1)}},
+			false,
+			false,
+		},
+		"nil_object": {nil, false, false},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			ready, err := isReady(test.obj)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if ready != test.expectedReady {
+				t.Fatalf("Expected isReady to return %v but got %v", test.expectedReady, ready)
+			}
+
+			current, err := isObservedGenerationCurrent(test.obj)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if current != test.expectedCurrent {
+				t.Fatalf("Expected isObservedGenerationCurrent to return %v but got %v", test.expectedCurrent, current)
+			}
+		})
+	}
+
+	if _, err := isReady([]interface{}{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error but got: %v", err)
+	}
+}
+
+func TestConditionFunctionsTemplate(t *testing.T) {
+	t.Parallel()
+
+	doResolveTest(t, resolveTestCase{
+		inputTmpl: `{{ $crd := fromJson .CRD }}` +
+			`ready: '{{ isReady $crd }}'
+status: '{{ $crd | conditionStatus "Established" }}'
+reason: '{{ $crd | conditionReason "Established" }}'
+missing: '{{ lookup "v1" "ConfigMap" "testns" "does-not-exist" | isReady }}'`,
+		ctx: struct{ CRD string }{
+			`{"kind": "CustomResourceDefinition", "status": {"conditions": ` +
+				`[{"type": "Established", "status": "True", "reason": "InitialNamesAccepted"}]}}`,
+		},
+		resolveOptions: ResolveOptions{InputIsYAML: true},
+		expectedResult: "missing: \"false\"\nready: \"true\"\nreason: InitialNamesAccepted\nstatus: \"True\"",
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"github.com/stolostron/kubernetes-dependency-watches/client"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// CustomFunctionFactory returns a custom template function for a single ResolveTemplate call. The returned value must
+// be a valid text/template function. The input Resolution can be used by the function to query the Kubernetes API the
+// same way the built-in functions do.
+type CustomFunctionFactory func(resolution Resolution) interface{}
+
+// Resolution provides access to the state of a single ResolveTemplate call. It is passed to the
+// ResolveOptions.CustomFunctionFactories so that custom template functions can behave like the built-in functions.
+//
+// The methods of the embedded CachingQueryAPI and LabelSelectorQueryAPI honor the LookupNamespace, LookupNamespaces,
+// LookupNamespaceSelector, and ClusterScopedAllowList options. When caching is enabled, the queried objects are watched
+// and a change to them will trigger a reconcile of the watcher, just like the "lookup" template function. Querying a
+// Secret automatically sets HasSensitiveData on the TemplateResult.
+type Resolution interface {
+	CachingQueryAPI
+	LabelSelectorQueryAPI
+	// Options returns a copy of the options passed to ResolveTemplate.
+	Options() ResolveOptions
+	// SetHasSensitiveData sets HasSensitiveData to true on the TemplateResult. Use this when the custom function
+	// returns sensitive data that wasn't retrieved from a Secret through this API.
+	SetHasSensitiveData()
+	// AddDependency registers the input object, or the list query if the name is empty, as a dependency of the
+	// Watcher so that a change to it triggers a reconcile. Use this when the custom function derives its result from
+	// objects it doesn't query with Get or List. It's subject to the same restrictions as the query methods in both
+	// modes. When caching is disabled, no watch is added, but the object is still queried to check the restrictions.
+	AddDependency(objID client.ObjectIdentifier) error
+	// Watcher returns the identifier of the object that includes the templates. This is nil when caching is disabled.
+	Watcher() *client.ObjectIdentifier
+}
+
+type resolution struct {
+	cachingQueryAPI
+}
+
+func (r *resolution) Options() ResolveOptions {
+	return *r.options
+}
+
+func (r *resolution) SetHasSensitiveData() {
+	r.templateResult.HasSensitiveData = true
+}
+
+func (r *resolution) Watcher() *client.ObjectIdentifier {
+	if r.resolver.dynamicWatcher == nil {
+		return nil
+	}
+
+	return r.options.Watcher
+}
+
+func (r *resolution) AddDependency(objID client.ObjectIdentifier) error {
+	// The result is discarded, so don't pass the TemplateResult to avoid setting HasSensitiveData
+	_, err := r.resolver.getOrList(
+		r.options,
+		nil,
+		objID.GroupVersionKind().GroupVersion().String(),
+		objID.Kind,
+		objID.Namespace,
+		objID.Name,
+		objID.Selector,
+	)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
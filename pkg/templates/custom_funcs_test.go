@@ -0,0 +1,324 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/stolostron/kubernetes-dependency-watches/client"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	configMapGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	secretGVK    = schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+)
+
+func TestCustomFunctionFactories(t *testing.T) {
+	t.Parallel()
+
+	// getLabel returns the value of the input label on a ConfigMap
+	getLabel := func(res Resolution) interface{} {
+		return func(namespace, name, label string) (string, error) {
+			cm, err := res.Get(configMapGVK, namespace, name)
+			if err != nil || cm == nil {
+				return "", err
+			}
+
+			return cm.GetLabels()[label], nil
+		}
+	}
+
+	// countWithLabel returns the number of ConfigMaps matching the label selector
+	countWithLabel := func(res Resolution) interface{} {
+		return func(namespace, selector string) (int, error) {
+			parsed, err := labels.Parse(selector)
+			if err != nil {
+				return 0, err
+			}
+
+			cms, err := res.List(configMapGVK, namespace, parsed)
+
+			return len(cms), err
+		}
+	}
+
+	testcases := map[string]resolveTestCase{
+		"get": {
+			inputTmpl: `value: '{{ getLabel "testns" "testcm-enva" "env" }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctionFactories: map[string]CustomFunctionFactory{"getLabel": getLabel},
+			},
+			expectedResult: "value: a",
+		},
+		"get_not_found": {
+			inputTmpl: `value: '{{ getLabel "testns" "does-not-exist" "env" }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctionFactories: map[string]CustomFunctionFactory{"getLabel": getLabel},
+			},
+			expectedResult: `value: ""`,
+		},
+		"get_default_namespace": {
+			inputTmpl: `value: '{{ getLabel "" "testcm-envb" "env" }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctionFactories: map[string]CustomFunctionFactory{"getLabel": getLabel},
+				LookupNamespace:         "testns",
+			},
+			expectedResult: "value: b",
+		},
+		"get_restricted_namespace": {
+			inputTmpl: `value: '{{ getLabel "testns" "testcm-enva" "env" }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctionFactories: map[string]CustomFunctionFactory{"getLabel": getLabel},
+				LookupNamespace:         "policies",
+			},
+			expectedErr: ErrRestrictedNamespace,
+		},
+		"list": {
+			inputTmpl: `value: '{{ countWithLabel "testns" "app=test" }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctionFactories: map[string]CustomFunctionFactory{"countWithLabel": countWithLabel},
+			},
+			expectedResult: "value: \"3\"",
+		},
+		"options": {
+			inputTmpl: `value: '{{ lookupNamespace }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctionFactories: map[string]CustomFunctionFactory{
+					"lookupNamespace": func(res Resolution) interface{} {
+						return func() string { return res.Options().LookupNamespace }
+					},
+				},
+				LookupNamespace: "testns",
+			},
+			expectedResult: "value: testns",
+		},
+		"precedence": {
+			inputTmpl: `value: '{{ greet }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctions: template.FuncMap{"greet": func() string { return "hello" }},
+				CustomFunctionFactories: map[string]CustomFunctionFactory{
+					"greet": func(_ Resolution) interface{} {
+						return func() string { return "hi" }
+					},
+				},
+			},
+			expectedResult: "value: hi",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			doResolveTest(t, test)
+		})
+	}
+}
+
+func TestCustomFunctionFactoriesSensitiveData(t *testing.T) {
+	t.Parallel()
+
+	resolver, err := NewResolver(k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	tmplStr, err := yamlToJSON([]byte(`value: '{{ isSecret "testns" "testsecret" }}'`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	options := &ResolveOptions{
+		CustomFunctionFactories: map[string]CustomFunctionFactory{
+			"isSecret": func(res Resolution) interface{} {
+				return func(namespace, name string) (bool, error) {
+					secret, err := res.Get(secretGVK, namespace, name)
+
+					return secret != nil, err
+				}
+			},
+		},
+	}
+
+	result, err := resolver.ResolveTemplate(tmplStr, nil, options)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if string(result.ResolvedJSON) != `{"value":"true"}` {
+		t.Fatalf("Unexpected template: %s", string(result.ResolvedJSON))
+	}
+
+	if !result.HasSensitiveData {
+		t.Fatal("Expected HasSensitiveData to be set to true from getting a Secret")
+	}
+
+	options.CustomFunctionFactories = map[string]CustomFunctionFactory{
+		"secretWord": func(res Resolution) interface{} {
+			return func() string {
+				res.SetHasSensitiveData()
+
+				return "swordfish"
+			}
+		},
+	}
+
+	tmplStr, err = yamlToJSON([]byte(`value: '{{ secretWord }}'`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	result, err = resolver.ResolveTemplate(tmplStr, nil, options)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !result.HasSensitiveData {
+		t.Fatal("Expected HasSensitiveData to be set to true from SetHasSensitiveData")
+	}
+}
+
+func TestCustomFunctionFactoriesWithCaching(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	resolver, _, err := NewResolverWithCaching(ctx, k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	tmplStr, err := yamlToJSON([]byte(`value: '{{ getEnv "testcm-envc" }}'`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	watcher := client.ObjectIdentifier{
+		Version:   "v1",
+		Kind:      "ConfigMap",
+		Namespace: "testns",
+		Name:      "watcher",
+	}
+
+	var factoryWatcher *client.ObjectIdentifier
+
+	options := &ResolveOptions{
+		CustomFunctionFactories: map[string]CustomFunctionFactory{
+			"getEnv": func(res Resolution) interface{} {
+				factoryWatcher = res.Watcher()
+
+				return func(name string) (string, error) {
+					cm, err := res.Get(configMapGVK, "", name)
+					if err != nil {
+						return "", err
+					}
+
+					return cm.GetLabels()["env"], nil
+				}
+			},
+		},
+		LookupNamespace: "testns",
+		Watcher:         &watcher,
+	}
+
+	result, err := resolver.ResolveTemplate(tmplStr, nil, options)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if string(result.ResolvedJSON) != `{"value":"c"}` {
+		t.Fatalf("Unexpected template: %s", string(result.ResolvedJSON))
+	}
+
+	if factoryWatcher == nil || *factoryWatcher != watcher {
+		t.Fatalf("Expected the factory to receive the watcher but got %v", factoryWatcher)
+	}
+
+	if resolver.GetWatchCount() != 1 {
+		t.Fatalf("Expected a watch count of 1 but got: %d", resolver.GetWatchCount())
+	}
+}
+
+func TestCustomFunctionFactoriesAddDependency(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	resolver, _, err := NewResolverWithCaching(ctx, k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	watcher := client.ObjectIdentifier{
+		Version:   "v1",
+		Kind:      "ConfigMap",
+		Namespace: "testns",
+		Name:      "watcher",
+	}
+
+	// dependOn registers the input object as a dependency without querying it
+	options := &ResolveOptions{
+		CustomFunctionFactories: map[string]CustomFunctionFactory{
+			"dependOn": func(res Resolution) interface{} {
+				return func(namespace, name string) (string, error) {
+					return "", res.AddDependency(client.ObjectIdentifier{
+						Version: "v1", Kind: "ConfigMap", Namespace: namespace, Name: name,
+					})
+				}
+			},
+		},
+		LookupNamespace: "testns",
+		Watcher:         &watcher,
+	}
+
+	tmplStr, err := yamlToJSON([]byte(
+		`value: '{{ dependOn "testns" "testcm-enva" }}{{ dependOn "testns" "does-not-exist" }}'`,
+	))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := resolver.ResolveTemplate(tmplStr, nil, options); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if resolver.GetWatchCount() != 2 {
+		t.Fatalf("Expected a watch count of 2 but got: %d", resolver.GetWatchCount())
+	}
+
+	tmplStr, err = yamlToJSON([]byte(`value: '{{ dependOn "default" "testcm-enva" }}'`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = resolver.ResolveTemplate(tmplStr, nil, options)
+	if !errors.Is(err, ErrRestrictedNamespace) {
+		t.Fatalf("Expected a restricted namespace error but got: %v", err)
+	}
+
+	// Without caching, no watch is added but the restrictions still apply
+	resolver, err = NewResolver(k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := resolver.ResolveTemplate(tmplStr, nil, &ResolveOptions{
+		CustomFunctionFactories: options.CustomFunctionFactories,
+	}); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = resolver.ResolveTemplate(tmplStr, nil, options)
+	if !errors.Is(err, ErrRestrictedNamespace) {
+		t.Fatalf("Expected a restricted namespace error without caching but got: %v", err)
+	}
+}
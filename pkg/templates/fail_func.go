@@ -0,0 +1,61 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"text/template"
+)
+
+// execErrorPosition matches the position of the template action in a text/template execution error
+// (e.g. "template: tmpl:3:12: executing ...").
+var execErrorPosition = regexp.MustCompile(`^template: [^:]+:(\d+):(\d+): executing`)
+
+// TemplateFailError is returned by ResolveTemplate when the template calls the "fail" template function. This lets the
+// caller distinguish a failure defined by the template author from an infrastructure error (e.g.
+// ErrMissingAPIResource), and report the author's message directly.
+//
+// - Message is the message passed to the "fail" template function.
+//
+// - Line and Column are the position of the template action that called "fail" in the template being resolved. When
+// the input is JSON, this is the position in the YAML converted from the JSON. These are 0 if the position is unknown.
+type TemplateFailError struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+func (e *TemplateFailError) Error() string {
+	if e.Line == 0 {
+		return "the template failed: " + e.Message
+	}
+
+	return fmt.Sprintf("the template failed at line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// fail aborts the template resolution with the input message.
+func fail(message string) (string, error) {
+	return "", &TemplateFailError{Message: message}
+}
+
+// asTemplateFailError returns the TemplateFailError from the input template execution error with the position of the
+// template action set. If the error was not caused by the "fail" template function, nil is returned.
+func asTemplateFailError(err error) *TemplateFailError {
+	var failErr *TemplateFailError
+	if !errors.As(err, &failErr) {
+		return nil
+	}
+
+	var execErr template.ExecError
+	if errors.As(err, &execErr) {
+		if matches := execErrorPosition.FindStringSubmatch(execErr.Error()); matches != nil {
+			failErr.Line, _ = strconv.Atoi(matches[1])
+			failErr.Column, _ = strconv.Atoi(matches[2])
+		}
+	}
+
+	return failErr
+}
@@ -0,0 +1,107 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFail(t *testing.T) {
+	t.Parallel()
+
+	resolver, err := NewResolver(k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	testcases := map[string]struct {
+		inputTmpl      string
+		expectedLine   int
+		expectedColumn int
+		expectedMsg    string
+	}{
+		"fail": {
+			inputTmpl:      "value: '{{ fail \"the cluster is not supported\" }}'",
+			expectedLine:   1,
+			expectedColumn: 11,
+			expectedMsg:    "the cluster is not supported",
+		},
+		"fail_conditional": {
+			inputTmpl: "first: hello\n" +
+				"second: '{{ if not (lookup \"v1\" \"ConfigMap\" \"testns\" \"does-not-exist\") }}" +
+				"{{ fail \"the ConfigMap must exist\" }}{{ end }}'",
+			expectedLine:   2,
+			expectedColumn: 76,
+			expectedMsg:    "the ConfigMap must exist",
+		},
+		"fail_in_tpl": {
+			inputTmpl:      "value: '{{ tpl \"{{ fail \\\"nested\\\" }}\" }}'",
+			expectedLine:   1,
+			expectedColumn: 11,
+			expectedMsg:    "nested",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := resolver.ResolveTemplate([]byte(test.inputTmpl), nil, &ResolveOptions{InputIsYAML: true})
+
+			var failErr *TemplateFailError
+			if !errors.As(err, &failErr) {
+				t.Fatalf("Expected a TemplateFailError but got: %v", err)
+			}
+
+			if failErr.Message != test.expectedMsg {
+				t.Fatalf("Expected the message %q but got %q", test.expectedMsg, failErr.Message)
+			}
+
+			if failErr.Line != test.expectedLine || failErr.Column != test.expectedColumn {
+				t.Fatalf(
+					"Expected the position %d:%d but got %d:%d",
+					test.expectedLine, test.expectedColumn, failErr.Line, failErr.Column,
+				)
+			}
+		})
+	}
+}
+
+func TestFailNotCalled(t *testing.T) {
+	t.Parallel()
+
+	resolver, err := NewResolver(k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = resolver.ResolveTemplate(
+		[]byte(`value: '{{ lookup "v1" "NotAResource" "testns" "name" }}'`), nil, &ResolveOptions{InputIsYAML: true},
+	)
+
+	var failErr *TemplateFailError
+	if errors.As(err, &failErr) {
+		t.Fatalf("Expected an error that is not a TemplateFailError but got: %v", err)
+	}
+
+	if !errors.Is(err, ErrMissingAPIResource) {
+		t.Fatalf("Expected ErrMissingAPIResource but got: %v", err)
+	}
+}
+
+func TestTemplateFailErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	err := &TemplateFailError{Message: "oops", Line: 3, Column: 7}
+	if err.Error() != "the template failed at line 3, column 7: oops" {
+		t.Fatalf("Unexpected error message: %s", err.Error())
+	}
+
+	err = &TemplateFailError{Message: "oops"}
+	if err.Error() != "the template failed: oops" {
+		t.Fatalf("Unexpected error message: %s", err.Error())
+	}
+}
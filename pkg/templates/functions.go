@@ -0,0 +1,455 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// FunctionSource indicates where a template function is defined.
+type FunctionSource string
+
+const (
+	// FunctionSourceBuiltin is a function defined by this library.
+	FunctionSourceBuiltin FunctionSource = "builtin"
+	// FunctionSourceCustom is a function provided in ResolveOptions.CustomFunctions or
+	// ResolveOptions.CustomFunctionFactories.
+	FunctionSourceCustom FunctionSource = "custom"
+	// FunctionSourceGoTemplate is a function predefined by the text/template package.
+	FunctionSourceGoTemplate FunctionSource = "text/template"
+	// FunctionSourceSprig is a function exposed from the Sprig library.
+	FunctionSourceSprig FunctionSource = "sprig"
+)
+
+// FunctionInfo describes a template function that is available during template resolution.
+//
+// - Name is the name of the function as used in a template.
+//
+// - Description is a short description of the function. This is empty for custom functions.
+//
+// - Signature is the Go function signature (e.g. "func(string, string, string) (string, error)"). This is empty for
+// the functions predefined by the text/template package and for ResolveOptions.CustomFunctionFactories since the
+// factories are only called during template resolution.
+//
+// - Source indicates where the function is defined.
+//
+// - QueriesAPIServer is true if the function may query the Kubernetes API server. This is always false for custom
+// functions since it can't be determined.
+//
+// - ReturnsSensitiveData is true if the function may return sensitive data, such as the contents of a Secret. When such
+// a function is used, TemplateResult.HasSensitiveData is set.
+//
+// - EncryptsOutput is true if the function returns a value encrypted with the "protect" function. This depends on
+// ResolveOptions.EncryptionEnabled.
+type FunctionInfo struct {
+	Name                 string         `json:"name"`
+	Description          string         `json:"description,omitempty"`
+	Signature            string         `json:"signature,omitempty"`
+	Source               FunctionSource `json:"source"`
+	QueriesAPIServer     bool           `json:"queriesAPIServer"`
+	ReturnsSensitiveData bool           `json:"returnsSensitiveData"`
+	EncryptsOutput       bool           `json:"encryptsOutput"`
+}
+
+type functionMetadata struct {
+	description          string
+	queriesAPIServer     bool
+	returnsSensitiveData bool
+}
+
+// builtinFunctionMetadata describes the functions defined by this library. Every function added to the function map
+// in getFuncMap should have an entry here.
+var builtinFunctionMetadata = map[string]functionMetadata{
+	"addQuantity": {
+		description: "Returns the sum of the input resource quantities (e.g. 1Gi and 512Mi).",
+	},
+	"age": {
+		description: "Returns the duration since the input time, RFC 3339 timestamp, or object creationTimestamp.",
+	},
+	"atoi": {
+		description: "Parses an input string and returns an integer like the Atoi function.",
+	},
+	"autoindent": {
+		description: "Automatically indents the input string based on the leading spaces.",
+	},
+	"b64dec": {
+		description: "Decodes the input Base64 string to its decoded form. This is an alias of base64dec.",
+	},
+	"b64enc": {
+		description: "Encodes an input string in the Base64 format. This is an alias of base64enc.",
+	},
+	"base64dec": {
+		description: "Decodes the input Base64 string to its decoded form.",
+	},
+	"base64enc": {
+		description: "Encodes an input string in the Base64 format.",
+	},
+	"certificateDaysUntilExpiry": {
+		description: "Returns the number of days until the input PEM certificate expires.",
+	},
+	"cidrHost": {
+		description: "Returns the Nth address in the input CIDR, counting back from the end if N is negative.",
+	},
+	"cidrSubnets": {
+		description: "Returns the subnets with the input prefix length in the input CIDR.",
+	},
+	"cidrsOverlap": {
+		description: "Returns true if the two input CIDRs share any addresses.",
+	},
+	"compareQuantity": {
+		description: "Compares two resource quantities and returns -1, 0, or 1 if the first is less, equal, or more.",
+	},
+	"conditionMessage": {
+		description: "Returns the message of the status condition of the input type on the input object.",
+	},
+	"conditionReason": {
+		description: "Returns the reason of the status condition of the input type on the input object.",
+	},
+	"conditionStatus": {
+		description: "Returns the status (e.g. True) of the status condition of the input type on the input object.",
+	},
+	"copyConfigMapData": {
+		description:      "Returns the data contents of the specified ConfigMap.",
+		queriesAPIServer: true,
+	},
+	"copySecretData": {
+		description:          "Returns the data contents of the specified Secret.",
+		queriesAPIServer:     true,
+		returnsSensitiveData: true,
+	},
+	"date": {
+		description: "Formats the input date with the input layout, defaulting to the current time from the clock.",
+	},
+	"dedupeItems": {
+		description: "Returns the items of a list or lookup result without duplicates by field or entire item.",
+	},
+	"fail": {
+		description: "Aborts the template resolution with the input message, which is returned in a TemplateFailError.",
+	},
+	"filterItems": {
+		description: "Returns the items of a list or lookup result whose field equals the input value.",
+	},
+	"filterItemsByJSONPath": {
+		description: "Returns the items of a list or lookup result for which the JSONPath expression matches.",
+	},
+	"formatQuantity": {
+		description: "Formats the input resource quantity as BinarySI, DecimalSI, DecimalExponent, or in a unit.",
+	},
+	"fromClusterClaim": {
+		description:      "Returns the value of a specific ClusterClaim.",
+		queriesAPIServer: true,
+	},
+	"fromConfigMap": {
+		description:      "Returns the value of a key inside a ConfigMap.",
+		queriesAPIServer: true,
+	},
+	"fromSecret": {
+		description:          "Returns the value of a key inside a Secret.",
+		queriesAPIServer:     true,
+		returnsSensitiveData: true,
+	},
+	"getNodes": {
+		description:      "Returns a list of the nodes matching the input label selectors.",
+		queriesAPIServer: true,
+	},
+	"getNodesWithExactRoles": {
+		description:      "Returns a list of nodes with only the role(s) specified, ignoring the worker role.",
+		queriesAPIServer: true,
+	},
+	"groupItemsByLabel": {
+		description: "Returns a map of label values to the items of a list or lookup result with that label value.",
+	},
+	"hasAPIResource": {
+		description:      "Returns true if the API server serves the input apiVersion and kind.",
+		queriesAPIServer: true,
+	},
+	"hasNodesWithExactRoles": {
+		description:      "Returns true if the cluster contains node(s) with only the role(s) specified.",
+		queriesAPIServer: true,
+	},
+	"indent": {
+		description: "Indents the input string by the specified amount.",
+	},
+	"ipInCIDR": {
+		description: "Returns true if the input IP address is in the input CIDR.",
+	},
+	"isNamespacedAPIResource": {
+		description:      "Returns true if the input apiVersion and kind is namespaced.",
+		queriesAPIServer: true,
+	},
+	"isObservedGenerationCurrent": {
+		description: "Returns true if the status.observedGeneration of the input object matches its generation.",
+	},
+	"isReady": {
+		description: "Returns true if the input object (e.g. Deployment, Pod, CRD, or CSV) is ready.",
+	},
+	"jsonPatch": {
+		description: "Applies the input RFC 6902 JSON patch to a copy of the input object (e.g. a lookup result).",
+	},
+	"jsonpath": {
+		description: "Applies a Kubernetes JSONPath expression to the input value (e.g. a lookup result).",
+	},
+	"lookup": {
+		description:          "Generic lookup function for any Kubernetes object.",
+		queriesAPIServer:     true,
+		returnsSensitiveData: true,
+	},
+	"lookupAcrossNamespaces": {
+		description:          "Lists objects of a kind in the namespaces matching a namespace label selector.",
+		queriesAPIServer:     true,
+		returnsSensitiveData: true,
+	},
+	"lookupChildren": {
+		description:          "Returns a list of the objects of the input kind owned by the input owner or owner UID.",
+		queriesAPIServer:     true,
+		returnsSensitiveData: true,
+	},
+	"lookupOwner": {
+		description:          "Returns the owner of the input object from its metadata.ownerReferences.",
+		queriesAPIServer:     true,
+		returnsSensitiveData: true,
+	},
+	"lookupWithFieldSelector": {
+		description:          "Lists Kubernetes objects matching a field selector and optional label selectors.",
+		queriesAPIServer:     true,
+		returnsSensitiveData: true,
+	},
+	"mergePatch": {
+		description: "Applies the input RFC 7386 merge patch to a copy of the input object (e.g. a lookup result).",
+	},
+	"mulQuantity": {
+		description: "Returns the input resource quantity multiplied by the input factor (e.g. 0.8).",
+	},
+	"nodeAllocatable": {
+		description: "Returns the total allocatable amount of the input resource (e.g. cpu) of the input nodes.",
+	},
+	"nodeArchitectures": {
+		description: "Returns a map of CPU architectures to the number of input nodes with that architecture.",
+	},
+	"nodeCapacity": {
+		description: "Returns the total capacity of the input resource (e.g. memory) of the input nodes.",
+	},
+	"nodeCount": {
+		description: "Returns the number of input nodes.",
+	},
+	"nodeOperatingSystems": {
+		description: "Returns a map of operating systems to the number of input nodes with that operating system.",
+	},
+	"nodeRegions": {
+		description: "Returns the unique regions of the input nodes from the topology.kubernetes.io/region label.",
+	},
+	"nodeZones": {
+		description: "Returns the unique zones of the input nodes from the topology.kubernetes.io/zone label.",
+	},
+	"nodesWithTaint": {
+		description: "Returns the input nodes with a taint matching any of the input taints (key[=value][:effect]).",
+	},
+	"nodesWithoutTaint": {
+		description: "Returns the input nodes without a taint matching any of the input taints (key[=value][:effect]).",
+	},
+	"normalizeCIDR": {
+		description: "Returns the canonical form of the input CIDR with the host bits cleared.",
+	},
+	"normalizeIP": {
+		description: "Returns the canonical form of the input IPv4 or IPv6 address.",
+	},
+	"now": {
+		description: "Returns the current time from the clock configured on the resolver.",
+	},
+	"olderThan": {
+		description: "Returns true if the age of the input time, timestamp, or object exceeds the input duration.",
+	},
+	"parseCertificate": {
+		description: "Returns the subject, SANs, issuer, serial, and validity of the input PEM certificate.",
+	},
+	"parseDuration": {
+		description: "Parses the input duration string (e.g. 1h30m) like the ParseDuration function.",
+	},
+	"pluckItems": {
+		description: "Returns the values of the input field from the items of a list or lookup result.",
+	},
+	"protect": {
+		description: "Encrypts any string using AES-CBC.",
+	},
+	"serverVersion": {
+		description:      "Returns the Kubernetes version of the API server.",
+		queriesAPIServer: true,
+	},
+	"sortItems": {
+		description: "Returns the items of a list or lookup result sorted in ascending order by the input field.",
+	},
+	"strategicMergePatch": {
+		description: "Applies the input strategic merge patch to a copy of the input built-in Kubernetes object.",
+	},
+	"subQuantity": {
+		description: "Returns the first resource quantity minus the second resource quantity.",
+	},
+	"timestamp": {
+		description: "Converts the input RFC 3339 timestamp or object creationTimestamp to a time.",
+	},
+	"toBool": {
+		description: "Parses an input boolean string and removes any quotes around the map value.",
+	},
+	"toInt": {
+		description: "Parses an input string and returns an integer and removes any quotes around the map value.",
+	},
+	"toLiteral": {
+		description: "Removes any quotes around the template string after it is processed.",
+	},
+	"tpl": {
+		description: "Evaluates the input string as a template with the same functions, delimiters, and context.",
+	},
+	"verifyCertificate": {
+		description: "Returns true if the input PEM certificate is valid and chains to the input CA bundle.",
+	},
+}
+
+// goTemplateFunctions describes the functions predefined by the text/template package. When one of these is disabled,
+// it's overridden in the function map, so it takes precedence over this list.
+var goTemplateFunctions = map[string]string{
+	"and":      "Returns the boolean AND of its arguments.",
+	"call":     "Returns the result of calling the first argument, a function, with the remaining arguments.",
+	"eq":       "Returns the boolean truth of arg1 == arg2.",
+	"ge":       "Returns the boolean truth of arg1 >= arg2.",
+	"gt":       "Returns the boolean truth of arg1 > arg2.",
+	"html":     "Returns the escaped HTML equivalent of the textual representation of its arguments.",
+	"index":    "Returns the result of indexing its first argument by the following arguments.",
+	"js":       "Returns the escaped JavaScript equivalent of the textual representation of its arguments.",
+	"le":       "Returns the boolean truth of arg1 <= arg2.",
+	"len":      "Returns the integer length of its argument.",
+	"lt":       "Returns the boolean truth of arg1 < arg2.",
+	"ne":       "Returns the boolean truth of arg1 != arg2.",
+	"not":      "Returns the boolean negation of its single argument.",
+	"or":       "Returns the boolean OR of its arguments.",
+	"print":    "An alias for fmt.Sprint.",
+	"printf":   "An alias for fmt.Sprintf.",
+	"println":  "An alias for fmt.Sprintln.",
+	"slice":    "Returns the result of slicing its first argument by the remaining arguments.",
+	"urlquery": "Returns the escaped value of the textual representation of its arguments for a URL query.",
+}
+
+// encryptingFunctions are the functions that return encrypted values when ResolveOptions.EncryptionEnabled is set.
+var encryptingFunctions = map[string]bool{
+	"copySecretData": true,
+	"fromSecret":     true,
+	"protect":        true,
+}
+
+// AvailableFunctions returns a description of every template function available when resolving templates with the
+// input Config and ResolveOptions, sorted by name. This accounts for the allowed and disabled functions, the encryption
+// mode, and custom functions. The options argument may be nil. This is useful to generate documentation or power editor
+// completion.
+func AvailableFunctions(config Config, options *ResolveOptions) []FunctionInfo {
+	if options == nil {
+		options = &ResolveOptions{}
+	}
+
+	// The function map is only inspected and not executed, so Kubernetes clients aren't required. The custom function
+	// factories aren't called since they may query the API or have side effects.
+	resolver := &TemplateResolver{config: config}
+
+	funcMapOptions := *options
+	funcMapOptions.CustomFunctionFactories = nil
+
+	var templateCtx interface{}
+
+	funcMap := resolver.getFuncMap(&funcMapOptions, &TemplateResult{}, &templateCtx)
+
+	for name := range options.CustomFunctionFactories {
+		funcMap[name] = nil
+	}
+
+	sprigNames := resolver.sprigFunctionNames()
+
+	sprigFuncs := make(map[string]bool, len(sprigNames))
+	for _, fname := range sprigNames {
+		sprigFuncs[fname] = true
+	}
+
+	functions := make([]FunctionInfo, 0, len(funcMap)+len(goTemplateFunctions))
+
+	for name, fn := range funcMap {
+		info := FunctionInfo{Name: name}
+
+		if fn != nil {
+			info.Signature = reflect.TypeOf(fn).String()
+		}
+
+		_, isCustom := options.CustomFunctions[name]
+		if _, isFactory := options.CustomFunctionFactories[name]; isFactory {
+			isCustom = true
+		}
+
+		metadata, isBuiltin := builtinFunctionMetadata[name]
+
+		if !isCustom && resolver.disabledFunctionReason(options, name) != "" {
+			continue
+		}
+
+		switch {
+		case isCustom:
+			info.Source = FunctionSourceCustom
+		case isBuiltin:
+			// The protect function is only usable when encryption is enabled
+			if name == "protect" && !options.EncryptionEnabled {
+				continue
+			}
+
+			info.Source = FunctionSourceBuiltin
+			info.Description = metadata.description
+			info.QueriesAPIServer = metadata.queriesAPIServer
+			info.ReturnsSensitiveData = metadata.returnsSensitiveData
+			info.EncryptsOutput = options.EncryptionEnabled && encryptingFunctions[name]
+		case sprigFuncs[name]:
+			info.Source = FunctionSourceSprig
+			info.Description = "See the Sprig documentation at https://masterminds.github.io/sprig/."
+		}
+
+		functions = append(functions, info)
+	}
+
+	for name, description := range goTemplateFunctions {
+		// Functions in the function map take precedence over the predefined functions
+		if _, ok := funcMap[name]; ok {
+			continue
+		}
+
+		functions = append(functions, FunctionInfo{
+			Name:        name,
+			Description: description,
+			Source:      FunctionSourceGoTemplate,
+		})
+	}
+
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+
+	return functions
+}
+
+// disabledFunctionReason returns the reason the input default template function is disabled. An empty string is
+// returned if the function is enabled.
+func (t *TemplateResolver) disabledFunctionReason(options *ResolveOptions, name string) string {
+	switch {
+	case slices.Contains(t.config.DisabledFunctions, name):
+		return "disabled by the resolver configuration"
+	case slices.Contains(options.DisabledFunctions, name):
+		return "disabled for this template resolution"
+	case len(t.config.AllowedFunctions) != 0 && !slices.Contains(t.config.AllowedFunctions, name):
+		return "not in the allowed functions of the resolver configuration"
+	case len(options.AllowedFunctions) != 0 && !slices.Contains(options.AllowedFunctions, name):
+		return "not in the allowed functions for this template resolution"
+	}
+
+	return ""
+}
+
+// disabledFunction returns a template function that accepts any arguments and always returns an ErrFunctionDisabled
+// error with the function name and the reason it is disabled.
+func disabledFunction(name string, reason string) func(...interface{}) (interface{}, error) {
+	return func(...interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrFunctionDisabled, name, reason)
+	}
+}
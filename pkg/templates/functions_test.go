@@ -0,0 +1,135 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"sort"
+	"testing"
+	"text/template"
+)
+
+func functionsByName(functions []FunctionInfo) map[string]FunctionInfo {
+	byName := make(map[string]FunctionInfo, len(functions))
+
+	for _, function := range functions {
+		byName[function.Name] = function
+	}
+
+	return byName
+}
+
+func TestAvailableFunctions(t *testing.T) {
+	t.Parallel()
+
+	functions := AvailableFunctions(Config{}, nil)
+
+	if !sort.SliceIsSorted(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name }) {
+		t.Fatal("Expected the functions to be sorted by name")
+	}
+
+	byName := functionsByName(functions)
+
+	if len(byName) != len(functions) {
+		t.Fatal("Expected the function names to be unique")
+	}
+
+	for name := range builtinFunctionMetadata {
+		if name == "protect" {
+			continue
+		}
+
+		if byName[name].Source != FunctionSourceBuiltin {
+			t.Fatalf("Expected %s to be a builtin function but got: %v", name, byName[name])
+		}
+	}
+
+	if _, ok := byName["protect"]; ok {
+		t.Fatal("Expected protect to not be available when encryption is disabled")
+	}
+
+	fromSecret := byName["fromSecret"]
+	if !fromSecret.QueriesAPIServer || !fromSecret.ReturnsSensitiveData || fromSecret.EncryptsOutput {
+		t.Fatalf("Unexpected fromSecret metadata: %v", fromSecret)
+	}
+
+	if fromSecret.Signature != "func(string, string, string) (string, error)" {
+		t.Fatalf("Unexpected fromSecret signature: %s", fromSecret.Signature)
+	}
+
+	if byName["upper"].Source != FunctionSourceSprig {
+		t.Fatalf("Expected upper to be a Sprig function but got: %v", byName["upper"])
+	}
+
+	// Sprig overrides the slice function predefined by text/template
+	if byName["slice"].Source != FunctionSourceSprig {
+		t.Fatalf("Expected slice to be a Sprig function but got: %v", byName["slice"])
+	}
+
+	if byName["printf"].Source != FunctionSourceGoTemplate {
+		t.Fatalf("Expected printf to be a text/template function but got: %v", byName["printf"])
+	}
+}
+
+func TestAvailableFunctionsOptions(t *testing.T) {
+	t.Parallel()
+
+	config := Config{DisabledFunctions: []string{"lookup", "upper"}}
+	options := &ResolveOptions{
+		CustomFunctions: template.FuncMap{"greet": func(name string) string { return "hello " + name }},
+		CustomFunctionFactories: map[string]CustomFunctionFactory{
+			"indent": func(_ Resolution) interface{} {
+				panic("the factory must not be called")
+			},
+		},
+		EncryptionConfig: EncryptionConfig{EncryptionEnabled: true},
+	}
+
+	byName := functionsByName(AvailableFunctions(config, options))
+
+	for _, disabled := range config.DisabledFunctions {
+		if _, ok := byName[disabled]; ok {
+			t.Fatalf("Expected %s to be disabled", disabled)
+		}
+	}
+
+	greet := byName["greet"]
+	if greet.Source != FunctionSourceCustom || greet.Signature != "func(string) string" {
+		t.Fatalf("Unexpected greet metadata: %v", greet)
+	}
+
+	// A custom function overrides the builtin function with the same name
+	if byName["indent"].Source != FunctionSourceCustom || byName["indent"].Description != "" ||
+		byName["indent"].Signature != "" {
+		t.Fatalf("Expected indent to be a custom function but got: %v", byName["indent"])
+	}
+
+	for _, name := range []string{"fromSecret", "copySecretData", "protect"} {
+		if !byName[name].EncryptsOutput {
+			t.Fatalf("Expected %s to encrypt its output when encryption is enabled", name)
+		}
+	}
+
+	if byName["fromConfigMap"].EncryptsOutput {
+		t.Fatal("Expected fromConfigMap to not encrypt its output")
+	}
+}
+
+func TestAvailableFunctionsAllowed(t *testing.T) {
+	t.Parallel()
+
+	config := Config{AllowedFunctions: []string{"fromConfigMap", "upper", "lower"}, DisabledFunctions: []string{"eq"}}
+	options := &ResolveOptions{AllowedFunctions: []string{"fromConfigMap", "upper"}}
+
+	for _, function := range AvailableFunctions(config, options) {
+		switch function.Source {
+		case FunctionSourceGoTemplate:
+			if function.Name == "eq" {
+				t.Fatal("Expected eq to be disabled")
+			}
+		default:
+			if function.Name != "fromConfigMap" && function.Name != "upper" {
+				t.Fatalf("Expected %s to not be allowed", function.Name)
+			}
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -14,6 +16,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -29,25 +32,159 @@ func (e ClusterScopedLookupRestrictedError) Error() string {
 	return fmt.Sprintf("lookup of cluster-scoped resource '%v/%v' is not allowed", e.kind, e.name)
 }
 
-// getNamespace checks that the target namespace is allowed based on the configured
-// lookupNamespace. If it's not, an error is returned. It then returns the namespace
-// that should be used. If the target namespace is not set and the lookupNamespace
-// configuration is, then the namespace of lookupNamespace is returned for convenience.
-func (t *TemplateResolver) getNamespace(namespace string, lookupNamespace string) (string, error) {
-	// When lookupNamespace is an empty string, there are no namespace restrictions.
-	if lookupNamespace != "" {
-		// If lookupNamespace is set but namespace is an empty string, then default
-		// to lookupNamespace for convenience
-		if namespace == "" {
-			return lookupNamespace, nil
+// Is allows errors.Is(err, ErrClusterScopedLookupRestricted) to match any ClusterScopedLookupRestrictedError.
+func (e ClusterScopedLookupRestrictedError) Is(target error) bool {
+	return target == ErrClusterScopedLookupRestricted //nolint:errorlint
+}
+
+// LookupError is returned when a query to the Kubernetes API by a template function fails. It includes the identifier
+// of the object or list query and wraps the underlying error (e.g. ErrRestrictedNamespace, ErrMissingAPIResource, or
+// ClusterScopedLookupRestrictedError). Forbidden and timeout errors from the API server additionally match
+// ErrLookupForbidden and ErrLookupTimeout with errors.Is. The error message is the message of the underlying error.
+type LookupError struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Err        error
+	reason     error
+}
+
+func (e *LookupError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LookupError) Unwrap() []error {
+	if e.reason == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.reason, e.Err}
+}
+
+// newLookupError wraps the input error from a lookup in a LookupError and classifies forbidden and timeout errors.
+func newLookupError(apiVersion string, kind string, namespace string, name string, err error) *LookupError {
+	lookupErr := &LookupError{APIVersion: apiVersion, Kind: kind, Namespace: namespace, Name: name, Err: err}
+
+	switch {
+	case apierrors.IsForbidden(err):
+		lookupErr.reason = ErrLookupForbidden
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || errors.Is(err, context.DeadlineExceeded):
+		lookupErr.reason = ErrLookupTimeout
+	}
+
+	return lookupErr
+}
+
+// allowedLookupNamespaces returns the deduplicated namespaces from the LookupNamespace and LookupNamespaces options.
+func allowedLookupNamespaces(options *ResolveOptions) []string {
+	allowed := make([]string, 0, len(options.LookupNamespaces)+1)
+
+	if options.LookupNamespace != "" {
+		allowed = append(allowed, options.LookupNamespace)
+	}
+
+	for _, ns := range options.LookupNamespaces {
+		if ns != "" && !slices.Contains(allowed, ns) {
+			allowed = append(allowed, ns)
 		}
+	}
+
+	return allowed
+}
 
-		if lookupNamespace != namespace {
-			return "", fmt.Errorf("%w to %s", ErrRestrictedNamespace, lookupNamespace)
+// lookupNamespaceRestricted returns true if the input options restrict the namespaces that lookups can use.
+func lookupNamespaceRestricted(options *ResolveOptions) bool {
+	return options.LookupNamespace != "" || len(options.LookupNamespaces) != 0 || options.LookupNamespaceSelector != ""
+}
+
+// defaultLookupNamespace returns the namespace to use when a lookup doesn't specify one. This is only set when the
+// options restrict lookups to a single namespace.
+func defaultLookupNamespace(options *ResolveOptions) string {
+	allowed := allowedLookupNamespaces(options)
+	if len(allowed) == 1 && options.LookupNamespaceSelector == "" {
+		return allowed[0]
+	}
+
+	return ""
+}
+
+// getNamespace checks that the target namespace is allowed based on the configured LookupNamespace,
+// LookupNamespaces, and LookupNamespaceSelector options. If it's not, an error is returned. It then returns the
+// namespace that should be used. If the target namespace is not set and the options restrict lookups to a single
+// namespace, then that namespace is returned for convenience. Otherwise, an empty string is returned and it's up to
+// the caller to require a namespace for namespaced resources.
+func (t *TemplateResolver) getNamespace(options *ResolveOptions, namespace string) (string, error) {
+	// When no restrictions are set, all namespaces are allowed.
+	if !lookupNamespaceRestricted(options) {
+		return namespace, nil
+	}
+
+	if namespace == "" {
+		return defaultLookupNamespace(options), nil
+	}
+
+	allowed := allowedLookupNamespaces(options)
+	if slices.Contains(allowed, namespace) {
+		return namespace, nil
+	}
+
+	if options.LookupNamespaceSelector != "" {
+		matches, err := t.namespaceMatchesSelector(options, namespace)
+		if err != nil {
+			return "", err
+		}
+
+		if matches {
+			return namespace, nil
 		}
 	}
 
-	return namespace, nil
+	return "", restrictedNamespaceError(options)
+}
+
+// namespaceMatchesSelector returns true if the input namespace exists and its labels match the
+// LookupNamespaceSelector option. The Namespace is retrieved like any other lookup, so it's cached and watched.
+func (t *TemplateResolver) namespaceMatchesSelector(options *ResolveOptions, namespace string) (bool, error) {
+	selector, err := labels.Parse(options.LookupNamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("%w: the lookup namespace selector is invalid: %w", ErrInvalidInput, err)
+	}
+
+	// The Namespace query itself is not subject to the lookup restrictions
+	nsObj, err := t.getOrList(&ResolveOptions{Watcher: options.Watcher}, nil, "v1", "Namespace", "", namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if nsObj == nil {
+		return false, nil
+	}
+
+	return selector.Matches(labels.Set((&unstructured.Unstructured{Object: nsObj}).GetLabels())), nil
+}
+
+// restrictedNamespaceError returns an ErrRestrictedNamespace error describing the namespaces allowed by the options.
+func restrictedNamespaceError(options *ResolveOptions) error {
+	allowed := strings.Join(allowedLookupNamespaces(options), ", ")
+
+	switch {
+	case options.LookupNamespaceSelector == "":
+		return fmt.Errorf("%w to %s", ErrRestrictedNamespace, allowed)
+	case allowed == "":
+		return fmt.Errorf(
+			"%w to namespaces matching the label selector %s", ErrRestrictedNamespace, options.LookupNamespaceSelector,
+		)
+	default:
+		return fmt.Errorf(
+			"%w to %s or namespaces matching the label selector %s",
+			ErrRestrictedNamespace, allowed, options.LookupNamespaceSelector,
+		)
+	}
 }
 
 func (t *TemplateResolver) getOrList(
@@ -60,16 +197,44 @@ func (t *TemplateResolver) getOrList(
 	labelSelector ...string,
 ) (
 	map[string]interface{}, error,
+) {
+	return t.getOrListWithFieldSelector(
+		options, templateResult, apiVersion, kind, namespace, name, "", labelSelector...,
+	)
+}
+
+// getOrListWithFieldSelector is like getOrList but also accepts a field selector (e.g. "status.phase=Running") for
+// list queries. Only the fields that the API server supports for the kind can be used. When caching is disabled, the
+// API server evaluates the field selector. When caching is enabled, the field selector is evaluated against the watched
+// objects since the watches are only based on label selectors, so every object matching the label selectors is still
+// watched.
+func (t *TemplateResolver) getOrListWithFieldSelector(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+	apiVersion string,
+	kind string,
+	namespace string,
+	name string,
+	fieldSelector string,
+	labelSelector ...string,
+) (
+	result map[string]interface{}, err error,
 ) {
 	if options == nil {
 		options = &ResolveOptions{}
 	}
 
+	defer func() {
+		if err != nil {
+			err = newLookupError(apiVersion, kind, namespace, name, err)
+		}
+	}()
+
 	if apiVersion == "" || kind == "" {
 		return nil, errors.New("the apiVersion and kind are required")
 	}
 
-	ns, err := t.getNamespace(namespace, options.LookupNamespace)
+	ns, err := t.getNamespace(options, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -98,35 +263,107 @@ func (t *TemplateResolver) getOrList(
 		}
 	}
 
-	var scopedGVRObj client.ScopedGVR
-	if t.dynamicWatcher != nil {
-		scopedGVRObj, err = t.dynamicWatcher.GVKToGVR(gvk)
-	} else {
-		scopedGVRObj, err = t.tempCallCache.GVKToGVR(gvk)
-	}
+	parsedFieldSelector := fields.Everything()
 
-	if err != nil {
-		if errors.Is(err, client.ErrNoVersionedResource) {
-			return nil, ErrMissingAPIResource
+	if fieldSelector != "" {
+		parsedFieldSelector, err = fields.ParseSelector(fieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: the field selector %s is invalid: %w", ErrInvalidInput, fieldSelector, err)
+		}
+
+		if err := validateFieldSelector(gvk, parsedFieldSelector); err != nil {
+			return nil, err
 		}
+	}
 
+	scopedGVRObj, err := t.gvkToGVR(gvk)
+	if err != nil {
 		return nil, err
 	}
 
-	if !scopedGVRObj.Namespaced && options.LookupNamespace != "" {
+	if scopedGVRObj.Namespaced && ns == "" && lookupNamespaceRestricted(options) {
+		// Listing across all namespaces would bypass the restrictions
+		return nil, fmt.Errorf("%w: a namespace must be specified", restrictedNamespaceError(options))
+	}
+
+	var allowlistSelectors []labels.Selector
+
+	if !scopedGVRObj.Namespaced && lookupNamespaceRestricted(options) {
 		rsrcIdentifier := ClusterScopedObjectIdentifier{
 			Group: scopedGVRObj.Group,
 			Kind:  kind,
 			Name:  name,
 		}
-		if !onAllowlist(options.ClusterScopedAllowList, rsrcIdentifier) {
+
+		allowed, selectors, err := onAllowlist(options.ClusterScopedAllowList, rsrcIdentifier)
+		if err != nil {
+			return nil, err
+		}
+
+		if !allowed {
 			return nil, ClusterScopedLookupRestrictedError{kind, name}
 		}
 
-		// If the namespace is restricted but this is a cluster scoped resource, unset the namespace.
+		allowlistSelectors = selectors
+	}
+
+	// The namespace is ignored for cluster-scoped resources
+	if !scopedGVRObj.Namespaced {
 		ns = ""
 	}
 
+	result, err = t.queryAPI(
+		options, templateResult, gvk, scopedGVRObj, ns, name, parsedSelector, parsedFieldSelector,
+	)
+	if err != nil || result == nil || len(allowlistSelectors) == 0 {
+		return result, err
+	}
+
+	return filterByAllowlistSelectors(result, kind, name, allowlistSelectors)
+}
+
+// gvkToGVR returns the resource and scope of the input GVK using the discovery information of the dynamic watcher
+// when caching is enabled or the temporary call cache otherwise. ErrMissingAPIResource is returned if the API server
+// doesn't serve the GVK.
+func (t *TemplateResolver) gvkToGVR(gvk schema.GroupVersionKind) (client.ScopedGVR, error) {
+	var (
+		scopedGVRObj client.ScopedGVR
+		err          error
+	)
+
+	if t.dynamicWatcher != nil {
+		scopedGVRObj, err = t.dynamicWatcher.GVKToGVR(gvk)
+	} else {
+		scopedGVRObj, err = t.tempCallCache.GVKToGVR(gvk)
+	}
+
+	if err != nil {
+		if errors.Is(err, client.ErrNoVersionedResource) {
+			return client.ScopedGVR{}, ErrMissingAPIResource
+		}
+
+		return client.ScopedGVR{}, err
+	}
+
+	return scopedGVRObj, nil
+}
+
+// queryAPI gets or lists the objects after the restrictions have been checked. The dynamic watcher is used when
+// caching is enabled. Otherwise, the dynamic client is used and the results are stored in the temporary call cache.
+func (t *TemplateResolver) queryAPI(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+	gvk schema.GroupVersionKind,
+	scopedGVRObj client.ScopedGVR,
+	ns string,
+	name string,
+	parsedSelector labels.Selector,
+	parsedFieldSelector fields.Selector,
+) (
+	map[string]interface{}, error,
+) {
+	kind := gvk.Kind
+
 	if t.dynamicWatcher != nil {
 		if name == "" {
 			result, err := t.dynamicWatcher.List(*options.Watcher, gvk, ns, parsedSelector)
@@ -134,6 +371,10 @@ func (t *TemplateResolver) getOrList(
 				return nil, err
 			}
 
+			if !parsedFieldSelector.Empty() {
+				result = filterByFieldSelector(result, gvk, parsedFieldSelector)
+			}
+
 			resultList := unstructured.UnstructuredList{Items: result}
 
 			if templateResult != nil && kind == "Secret" && len(resultList.Items) > 0 {
@@ -169,6 +410,10 @@ func (t *TemplateResolver) getOrList(
 		Selector:  parsedSelector.String(),
 	}
 
+	if !parsedFieldSelector.Empty() {
+		return t.listWithFieldSelector(templateResult, scopedGVRObj, lookupID, parsedFieldSelector)
+	}
+
 	cachedResults, err := t.tempCallCache.FromObjectIdentifier(lookupID)
 	if err != nil {
 		if !errors.Is(err, client.ErrNoCacheEntry) {
@@ -240,6 +485,334 @@ func (t *TemplateResolver) getOrList(
 	return resultUnstructured.UnstructuredContent(), nil
 }
 
+func (t *TemplateResolver) lookupAcrossNamespacesHelper(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+) func(string, string, string, string, ...string) (map[string]interface{}, error) {
+	return func(
+		apiVersion string,
+		kind string,
+		namespaceSelector string,
+		name string,
+		labelSelector ...string,
+	) (map[string]interface{}, error) {
+		return t.lookupAcrossNamespaces(
+			options, templateResult, apiVersion, kind, namespaceSelector, name, labelSelector...,
+		)
+	}
+}
+
+// lookupAcrossNamespaces returns a list of the objects of the input namespaced kind in every namespace matching the
+// namespace label selector. If a name is provided, the list contains the object with that name from each namespace
+// where it exists. Otherwise, the objects matching the optional label selectors are listed from each namespace. Each
+// namespace is queried like the "lookup" template function, so the LookupIgnoreForbidden,
+// LookupIgnoreMissingAPIResource, and StrictMode options apply to each query. This means that in strict mode, the
+// named object must exist in every matching namespace. The Namespaces and the objects are cached and watched when
+// caching is enabled.
+func (t *TemplateResolver) lookupAcrossNamespaces(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+	apiVersion string,
+	kind string,
+	namespaceSelector string,
+	name string,
+	labelSelector ...string,
+) (
+	map[string]interface{}, error,
+) {
+	klog.V(2).Infof("lookupAcrossNamespaces :  %v, %v, %v, %v", apiVersion, kind, namespaceSelector, name)
+
+	if options == nil {
+		options = &ResolveOptions{}
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the API version %s is invalid: %w", ErrInvalidInput, apiVersion, err)
+	}
+
+	// A missing API resource is handled by the lookups below based on the options
+	scopedGVRObj, err := t.gvkToGVR(gv.WithKind(kind))
+	if err != nil && !errors.Is(err, ErrMissingAPIResource) {
+		return nil, newLookupError(apiVersion, kind, "", name, err)
+	}
+
+	if err == nil && !scopedGVRObj.Namespaced {
+		return nil, fmt.Errorf("%w: %s %s is cluster-scoped, use lookup instead", ErrInvalidInput, apiVersion, kind)
+	}
+
+	namespaceNames, err := t.namespacesMatchingSelector(options, namespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	resultList := unstructured.UnstructuredList{Items: []unstructured.Unstructured{}}
+
+	for _, namespace := range namespaceNames {
+		result, err := t.lookup(options, templateResult, apiVersion, kind, namespace, name, labelSelector...)
+		if err != nil {
+			return nil, err
+		}
+
+		if result == nil {
+			continue
+		}
+
+		if name != "" {
+			resultList.Items = append(resultList.Items, unstructured.Unstructured{Object: result})
+
+			continue
+		}
+
+		namespaceResults := unstructured.UnstructuredList{}
+		namespaceResults.SetUnstructuredContent(result)
+
+		resultList.Items = append(resultList.Items, namespaceResults.Items...)
+	}
+
+	return resultList.UnstructuredContent(), nil
+}
+
+// namespacesMatchingSelector returns the sorted names of the namespaces matching the input label selector that the
+// lookup restrictions allow. When the lookups are restricted, only the allowed namespaces are queried, so the
+// Namespace queries don't require a ClusterScopedAllowList entry. Otherwise, all the Namespaces matching the selector
+// are listed.
+func (t *TemplateResolver) namespacesMatchingSelector(
+	options *ResolveOptions, namespaceSelector string,
+) ([]string, error) {
+	selector, err := labels.Parse(namespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the namespace selector %s is invalid: %w", ErrInvalidInput, namespaceSelector, err)
+	}
+
+	// The Namespace queries are not subject to the lookup restrictions since they are limited to the allowed
+	// namespaces below
+	namespaceOptions := &ResolveOptions{Watcher: options.Watcher}
+
+	namespaceNames := []string{}
+
+	if !lookupNamespaceRestricted(options) {
+		namespaces, err := t.getOrList(namespaceOptions, nil, "v1", "Namespace", "", "", namespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		namespaceList := unstructured.UnstructuredList{}
+		namespaceList.SetUnstructuredContent(namespaces)
+
+		for _, namespace := range namespaceList.Items {
+			namespaceNames = append(namespaceNames, namespace.GetName())
+		}
+
+		slices.Sort(namespaceNames)
+
+		return namespaceNames, nil
+	}
+
+	for _, namespace := range allowedLookupNamespaces(options) {
+		nsObj, err := t.getOrList(namespaceOptions, nil, "v1", "Namespace", "", namespace)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		if nsObj != nil && selector.Matches(labels.Set((&unstructured.Unstructured{Object: nsObj}).GetLabels())) {
+			namespaceNames = append(namespaceNames, namespace)
+		}
+	}
+
+	if options.LookupNamespaceSelector != "" {
+		lookupNamespaceSelector, err := labels.Parse(options.LookupNamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: the lookup namespace selector is invalid: %w", ErrInvalidInput, err)
+		}
+
+		requirements, _ := lookupNamespaceSelector.Requirements()
+		combinedSelector := selector.Add(requirements...)
+
+		namespaces, err := t.getOrList(
+			namespaceOptions, nil, "v1", "Namespace", "", "", combinedSelector.String(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		namespaceList := unstructured.UnstructuredList{}
+		namespaceList.SetUnstructuredContent(namespaces)
+
+		for _, namespace := range namespaceList.Items {
+			if !slices.Contains(namespaceNames, namespace.GetName()) {
+				namespaceNames = append(namespaceNames, namespace.GetName())
+			}
+		}
+	}
+
+	slices.Sort(namespaceNames)
+
+	return namespaceNames, nil
+}
+
+// fieldSelectorCacheKey is the key of a list query with a field selector in the temporary field selector cache. The
+// client.ObjectIdentifier used by the temporary call cache doesn't support field selectors, so these list queries are
+// cached separately.
+type fieldSelectorCacheKey struct {
+	client.ObjectIdentifier
+	FieldSelector string
+}
+
+// listWithFieldSelector lists the objects matching the label selector in the lookup ID and the field selector using the
+// dynamic client. The API server evaluates the field selector and the results are cached for the rest of the
+// ResolveTemplate call. This is only used when caching is disabled.
+func (t *TemplateResolver) listWithFieldSelector(
+	templateResult *TemplateResult,
+	scopedGVRObj client.ScopedGVR,
+	lookupID client.ObjectIdentifier,
+	fieldSelector fields.Selector,
+) (
+	map[string]interface{}, error,
+) {
+	cacheKey := fieldSelectorCacheKey{ObjectIdentifier: lookupID, FieldSelector: fieldSelector.String()}
+
+	var items []unstructured.Unstructured
+
+	if cachedResults, ok := t.fieldSelectorCache.Load(cacheKey); ok {
+		items = cachedResults.([]unstructured.Unstructured)
+	} else {
+		var dynamicClientRes dynamic.ResourceInterface = t.dynamicClient.Resource(scopedGVRObj.GroupVersionResource)
+
+		if scopedGVRObj.Namespaced && lookupID.Namespace != "" {
+			dynamicClientRes = t.dynamicClient.Resource(scopedGVRObj.GroupVersionResource).Namespace(lookupID.Namespace)
+		}
+
+		resultUnstructuredList, err := dynamicClientRes.List(
+			context.TODO(),
+			metav1.ListOptions{LabelSelector: lookupID.Selector, FieldSelector: fieldSelector.String()},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = resultUnstructuredList.Items
+
+		t.fieldSelectorCache.Store(cacheKey, items)
+	}
+
+	// Copy the cached objects to match the temporary call cache
+	resultList := unstructured.UnstructuredList{Items: make([]unstructured.Unstructured, 0, len(items))}
+
+	for _, item := range items {
+		resultList.Items = append(resultList.Items, *item.DeepCopy())
+	}
+
+	if templateResult != nil && lookupID.Kind == "Secret" && len(resultList.Items) > 0 {
+		templateResult.HasSensitiveData = true
+	}
+
+	return resultList.UnstructuredContent(), nil
+}
+
+// clearFieldSelectorCache removes the list queries with field selectors cached during a ResolveTemplate call.
+func (t *TemplateResolver) clearFieldSelectorCache() {
+	t.fieldSelectorCache.Range(func(key, _ interface{}) bool {
+		t.fieldSelectorCache.Delete(key)
+
+		return true
+	})
+}
+
+// supportedFieldSelectors are the fields, in addition to metadata.name and metadata.namespace, that the Kubernetes
+// API server supports in field selectors for the built-in kinds.
+var supportedFieldSelectors = map[schema.GroupVersionKind][]string{
+	{Version: "v1", Kind: "Event"}: {
+		"involvedObject.apiVersion", "involvedObject.fieldPath", "involvedObject.kind", "involvedObject.name",
+		"involvedObject.namespace", "involvedObject.resourceVersion", "involvedObject.uid", "reason",
+		"reportingComponent", "type",
+	},
+	{Version: "v1", Kind: "Namespace"}: {"status.phase"},
+	{Version: "v1", Kind: "Node"}:      {"spec.unschedulable"},
+	{Version: "v1", Kind: "Pod"}: {
+		"spec.hostNetwork", "spec.nodeName", "spec.restartPolicy", "spec.schedulerName", "spec.serviceAccountName",
+		"status.nominatedNodeName", "status.phase", "status.podIP",
+	},
+	{Version: "v1", Kind: "ReplicationController"}:                                   {"status.replicas"},
+	{Version: "v1", Kind: "Secret"}:                                                  {"type"},
+	{Group: "apps", Version: "v1", Kind: "ReplicaSet"}:                               {"status.replicas"},
+	{Group: "batch", Version: "v1", Kind: "Job"}:                                     {"status.successful"},
+	{Group: "certificates.k8s.io", Version: "v1", Kind: "CertificateSigningRequest"}: {"spec.signerName"},
+}
+
+// fieldSelectorDefaults are the values that the Kubernetes API server uses in field selectors for the supported fields
+// that are omitted from the object when they have the zero value. Missing string fields are treated as an empty string.
+var fieldSelectorDefaults = map[schema.GroupVersionKind]map[string]string{
+	{Version: "v1", Kind: "Node"}:                      {"spec.unschedulable": "false"},
+	{Version: "v1", Kind: "Pod"}:                       {"spec.hostNetwork": "false"},
+	{Version: "v1", Kind: "ReplicationController"}:     {"status.replicas": "0"},
+	{Group: "apps", Version: "v1", Kind: "ReplicaSet"}: {"status.replicas": "0"},
+	{Group: "batch", Version: "v1", Kind: "Job"}:       {"status.successful": "0"},
+}
+
+// fieldSelectorPaths are the paths in the object of the supported fields whose field selector name doesn't match the
+// path.
+var fieldSelectorPaths = map[schema.GroupVersionKind]map[string]string{
+	{Group: "batch", Version: "v1", Kind: "Job"}: {"status.successful": "status.succeeded"},
+}
+
+// validateFieldSelector returns an ErrInvalidInput error if the field selector uses a field that the Kubernetes API
+// server doesn't support for the input kind. This is checked regardless of whether caching is enabled, since the
+// field selector is evaluated on the watched objects when caching is enabled, so that a template behaves the same
+// either way.
+func validateFieldSelector(gvk schema.GroupVersionKind, fieldSelector fields.Selector) error {
+	for _, requirement := range fieldSelector.Requirements() {
+		if requirement.Field == "metadata.name" || requirement.Field == "metadata.namespace" {
+			continue
+		}
+
+		if !slices.Contains(supportedFieldSelectors[gvk], requirement.Field) {
+			return fmt.Errorf(
+				"%w: the field selector field %s is not supported for %s", ErrInvalidInput, requirement.Field, gvk,
+			)
+		}
+	}
+
+	return nil
+}
+
+// filterByFieldSelector returns the input objects that match the field selector like the Kubernetes API server. Each
+// field in the selector is a dot-separated path in the object (e.g. "spec.nodeName"). A missing field has the value
+// that the API server uses for it from fieldSelectorDefaults, or an empty string otherwise.
+func filterByFieldSelector(
+	objects []unstructured.Unstructured, gvk schema.GroupVersionKind, selector fields.Selector,
+) []unstructured.Unstructured {
+	filtered := make([]unstructured.Unstructured, 0, len(objects))
+
+	for _, obj := range objects {
+		fieldSet := fields.Set{}
+
+		for _, requirement := range selector.Requirements() {
+			path := requirement.Field
+			if mappedPath, ok := fieldSelectorPaths[gvk][requirement.Field]; ok {
+				path = mappedPath
+			}
+
+			value, found, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(path, ".")...)
+			if err == nil && found && value != nil {
+				fieldSet[requirement.Field] = fmt.Sprint(value)
+			} else {
+				fieldSet[requirement.Field] = fieldSelectorDefaults[gvk][requirement.Field]
+			}
+		}
+
+		if selector.Matches(fieldSet) {
+			filtered = append(filtered, obj)
+		}
+	}
+
+	return filtered
+}
+
 func (t *TemplateResolver) lookupHelper(
 	options *ResolveOptions,
 	templateResult *TemplateResult,
@@ -266,40 +839,245 @@ func (t *TemplateResolver) lookup(
 ) (
 	map[string]interface{}, error,
 ) {
-	klog.V(2).Infof("lookup :  %v, %v, %v, %v", apiVersion, kind, namespace, name)
+	return t.lookupWithSelectors(options, templateResult, apiVersion, kind, namespace, name, "", labelSelector...)
+}
 
-	result, lookupErr := t.getOrList(options, templateResult, apiVersion, kind, namespace, name, labelSelector...)
+func (t *TemplateResolver) lookupWithFieldSelectorHelper(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+) func(string, string, string, string, ...string) (map[string]interface{}, error) {
+	return func(
+		apiVersion string,
+		kind string,
+		namespace string,
+		fieldSelector string,
+		labelSelector ...string,
+	) (map[string]interface{}, error) {
+		return t.lookupWithSelectors(
+			options, templateResult, apiVersion, kind, namespace, "", fieldSelector, labelSelector...,
+		)
+	}
+}
+
+// lookupWithSelectors implements the "lookup" and "lookupWithFieldSelector" template functions. Errors that the
+// options say to ignore are returned as empty results with a warning. The field selector is a separate template
+// function rather than an argument to "lookup" since the variadic arguments of "lookup" are label selectors, so a
+// field selector couldn't be distinguished from a label selector such as "tier=web".
+func (t *TemplateResolver) lookupWithSelectors(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+	apiVersion string,
+	kind string,
+	namespace string,
+	name string,
+	fieldSelector string,
+	labelSelector ...string,
+) (
+	map[string]interface{}, error,
+) {
+	klog.V(2).Infof("lookup :  %v, %v, %v, %v, %v", apiVersion, kind, namespace, name, fieldSelector)
+
+	result, lookupErr := t.getOrListWithFieldSelector(
+		options, templateResult, apiVersion, kind, namespace, name, fieldSelector, labelSelector...,
+	)
+
+	// In strict mode, a lookup of a single object that doesn't exist is an error. Note that a cached not found
+	// result is returned as a nil result and no error.
+	if options != nil && options.StrictMode && name != "" &&
+		(apierrors.IsNotFound(lookupErr) || (lookupErr == nil && result == nil)) {
+		objectID := name
+		if ns := t.resolvedNamespace(options, apiVersion, kind, namespace); ns != "" {
+			objectID = ns + "/" + name
+		}
+
+		return nil, fmt.Errorf("%w: %s %s %s", ErrObjectNotFound, apiVersion, kind, objectID)
+	}
 
 	// lookups don't fail on errors
 	if apierrors.IsNotFound(lookupErr) {
 		lookupErr = nil
 	}
 
+	if lookupErrorIgnored(options, templateResult, apiVersion, kind, namespace, name, lookupErr) {
+		// Match the results of a not found object or an empty list
+		if name != "" {
+			return nil, nil
+		}
+
+		return map[string]interface{}{"items": []interface{}{}}, nil
+	}
+
 	klog.V(2).Infof("lookup result:  %v", result)
 
 	return result, lookupErr
 }
 
-func onAllowlist(allowlist []ClusterScopedObjectIdentifier, rsrc ClusterScopedObjectIdentifier) bool {
-	if len(allowlist) == 0 {
+// lookupErrorIgnored returns true if the options say to ignore the input lookup error, in which case a warning is
+// added to the TemplateResult and the lookup should return an empty result.
+func lookupErrorIgnored(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+	apiVersion string,
+	kind string,
+	namespace string,
+	name string,
+	lookupErr error,
+) bool {
+	if lookupErr == nil || options == nil {
+		return false
+	}
+
+	var ignoreReason string
+
+	switch {
+	case options.LookupIgnoreForbidden && errors.Is(lookupErr, ErrLookupForbidden):
+		ignoreReason = "the lookup is forbidden"
+	case options.LookupIgnoreMissingAPIResource && errors.Is(lookupErr, ErrMissingAPIResource):
+		ignoreReason = "the API resource is not installed"
+	default:
 		return false
 	}
 
+	warning := fmt.Sprintf(
+		"the lookup of %s %s in namespace %q with name %q returned an empty result because %s",
+		apiVersion, kind, namespace, name, ignoreReason,
+	)
+
+	klog.V(2).Info(warning)
+
+	if templateResult != nil && !slices.Contains(templateResult.Warnings, warning) {
+		templateResult.Warnings = append(templateResult.Warnings, warning)
+	}
+
+	return true
+}
+
+// resolvedNamespace returns the namespace that a lookup with the input namespace queries. This accounts for the
+// default namespace from the options and cluster-scoped resources. The input namespace is returned if it can't be
+// determined.
+func (t *TemplateResolver) resolvedNamespace(
+	options *ResolveOptions, apiVersion string, kind string, namespace string,
+) string {
+	ns, err := t.getNamespace(options, namespace)
+	if err != nil {
+		return namespace
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return ns
+	}
+
+	if scopedGVRObj, err := t.gvkToGVR(gv.WithKind(kind)); err == nil && !scopedGVRObj.Namespaced {
+		return ""
+	}
+
+	return ns
+}
+
+// onAllowlist returns true if the input cluster-scoped resource matches an entry in the allowlist. If every matching
+// entry has a label selector, the selectors are returned and the caller must only return objects matching one of them.
+// An error is returned if a matching entry has an invalid regular expression or label selector.
+func onAllowlist(
+	allowlist []ClusterScopedObjectIdentifier, rsrc ClusterScopedObjectIdentifier,
+) (bool, []labels.Selector, error) {
+	allowed := false
+	selectors := []labels.Selector{}
+
 	for _, item := range allowlist {
-		if item.Group != "*" && item.Group != rsrc.Group {
+		if !matchesPattern(item.Group, rsrc.Group) || !matchesPattern(item.Kind, rsrc.Kind) {
 			continue
 		}
 
-		if item.Kind != "*" && item.Kind != rsrc.Kind {
+		if item.NameRegex != "" {
+			nameRegex, err := regexp.Compile("^(?:" + item.NameRegex + ")$")
+			if err != nil {
+				return false, nil, fmt.Errorf(
+					"%w: the ClusterScopedAllowList name regular expression %s is invalid: %w",
+					ErrInvalidInput, item.NameRegex, err,
+				)
+			}
+
+			if !nameRegex.MatchString(rsrc.Name) {
+				continue
+			}
+		} else if !matchesPattern(item.Name, rsrc.Name) {
 			continue
 		}
 
-		if item.Name == "*" || item.Name == rsrc.Name {
-			return true
+		// An entry without a label selector allows any object, so no filtering is needed
+		if item.LabelSelector == "" {
+			return true, nil, nil
+		}
+
+		selector, err := labels.Parse(item.LabelSelector)
+		if err != nil {
+			return false, nil, fmt.Errorf(
+				"%w: the ClusterScopedAllowList label selector %s is invalid: %w",
+				ErrInvalidInput, item.LabelSelector, err,
+			)
+		}
+
+		allowed = true
+
+		selectors = append(selectors, selector)
+	}
+
+	if !allowed {
+		return false, nil, nil
+	}
+
+	return true, selectors, nil
+}
+
+// matchesPattern returns true if the input value matches the glob pattern (e.g. "team-a-*"). An invalid pattern only
+// matches an identical value.
+func matchesPattern(pattern string, value string) bool {
+	if pattern == value {
+		return true
+	}
+
+	matched, err := path.Match(pattern, value)
+
+	return err == nil && matched
+}
+
+// filterByAllowlistSelectors restricts the input lookup result to the objects matching one of the input label
+// selectors from the ClusterScopedAllowList. When the result is a single object that doesn't match, a
+// ClusterScopedLookupRestrictedError is returned.
+func filterByAllowlistSelectors(
+	result map[string]interface{}, kind string, name string, selectors []labels.Selector,
+) (map[string]interface{}, error) {
+	matches := func(obj *unstructured.Unstructured) bool {
+		for _, selector := range selectors {
+			if selector.Matches(labels.Set(obj.GetLabels())) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if name != "" {
+		if !matches(&unstructured.Unstructured{Object: result}) {
+			return nil, ClusterScopedLookupRestrictedError{kind, name}
+		}
+
+		return result, nil
+	}
+
+	resultList := unstructured.UnstructuredList{}
+	resultList.SetUnstructuredContent(result)
+
+	filteredList := unstructured.UnstructuredList{Items: []unstructured.Unstructured{}}
+
+	for i := range resultList.Items {
+		if matches(&resultList.Items[i]) {
+			filteredList.Items = append(filteredList.Items, resultList.Items[i])
 		}
 	}
 
-	return false
+	return filteredList.UnstructuredContent(), nil
 }
 
 func (t *TemplateResolver) getNodesWithExactRolesHelper(
@@ -3,11 +3,23 @@
 package templates
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"slices"
 	"strings"
 	"testing"
+
+	"github.com/stolostron/kubernetes-dependency-watches/client"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 func TestLookup(t *testing.T) {
@@ -310,7 +322,7 @@ func TestLookupClusterScoped(t *testing.T) {
 			"Node",
 			"foo",
 			"policies-ns",
-			[]ClusterScopedObjectIdentifier{{"*", "*", "*"}},
+			[]ClusterScopedObjectIdentifier{{Group: "*", Kind: "*", Name: "*"}},
 			nil,
 			false,
 		},
@@ -320,7 +332,7 @@ func TestLookupClusterScoped(t *testing.T) {
 			"Node",
 			"foo",
 			"policies-ns",
-			[]ClusterScopedObjectIdentifier{{"", "Node", "*"}},
+			[]ClusterScopedObjectIdentifier{{Group: "", Kind: "Node", Name: "*"}},
 			nil,
 			false,
 		},
@@ -330,7 +342,7 @@ func TestLookupClusterScoped(t *testing.T) {
 			"Node",
 			"foo",
 			"policies-ns",
-			[]ClusterScopedObjectIdentifier{{"", "Node", "foo"}},
+			[]ClusterScopedObjectIdentifier{{Group: "", Kind: "Node", Name: "foo"}},
 			nil,
 			false,
 		},
@@ -341,7 +353,7 @@ func TestLookupClusterScoped(t *testing.T) {
 			"Node",
 			"foo",
 			"policies-ns",
-			[]ClusterScopedObjectIdentifier{{"", "Node", "bar"}},
+			[]ClusterScopedObjectIdentifier{{Group: "", Kind: "Node", Name: "bar"}},
 			clusterScopedErr,
 			false,
 		},
@@ -351,7 +363,7 @@ func TestLookupClusterScoped(t *testing.T) {
 			"Node",
 			"foo",
 			"policies-ns",
-			[]ClusterScopedObjectIdentifier{{"myapi.com", "Node", "foo"}},
+			[]ClusterScopedObjectIdentifier{{Group: "myapi.com", Kind: "Node", Name: "foo"}},
 			clusterScopedErr,
 			false,
 		},
@@ -498,3 +510,741 @@ func TestHasNodesWithExactRoles(t *testing.T) {
 		t.Fatal("Infra nodes should exist, but returned false")
 	}
 }
+
+// newFakeLookupResolver returns a non-caching TemplateResolver with fake clients serving ConfigMaps, Namespaces,
+// Nodes, Pods, Secrets, Deployments, and ReplicaSets. The input objects are added to the Namespaces and ConfigMaps
+// below. Getting the ConfigMap named "forbidden" returns a Forbidden error and getting the ConfigMap named "timeout"
+// returns a Timeout error.
+func newFakeLookupResolver(t *testing.T, extraObjects ...runtime.Object) *TemplateResolver {
+	t.Helper()
+
+	configMapsGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	namespacesGVR := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+	objects := append([]runtime.Object{}, extraObjects...)
+
+	// The tenant-a and tenant-b namespaces belong to tenant a and the other namespace belongs to tenant b
+	for ns, tenant := range map[string]string{"tenant-a": "a", "tenant-b": "a", "other": "b"} {
+		namespace := &unstructured.Unstructured{}
+		namespace.SetAPIVersion("v1")
+		namespace.SetKind("Namespace")
+		namespace.SetName(ns)
+		namespace.SetLabels(map[string]string{"tenant": tenant})
+
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetNamespace(ns)
+		configMap.SetName("config")
+
+		objects = append(objects, namespace, configMap)
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			configMapsGVR:                                           "ConfigMapList",
+			namespacesGVR:                                           "NamespaceList",
+			{Version: "v1", Resource: "nodes"}:                      "NodeList",
+			{Version: "v1", Resource: "pods"}:                       "PodList",
+			{Version: "v1", Resource: "secrets"}:                    "SecretList",
+			{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+			{Group: "apps", Version: "v1", Resource: "replicasets"}: "ReplicaSetList",
+		},
+		objects...,
+	)
+	dynamicClient.PrependReactor("get", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		name := action.(clienttesting.GetAction).GetName()
+
+		switch name {
+		case "forbidden":
+			return true, nil, apierrors.NewForbidden(configMapsGVR.GroupResource(), name, errors.New("no access"))
+		case "timeout":
+			return true, nil, apierrors.NewTimeoutError("the request timed out", 1)
+		default:
+			return false, nil, nil
+		}
+	})
+
+	discoveryClient := &discoveryfake.FakeDiscovery{
+		Fake: &clienttesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+						{Name: "namespaces", Kind: "Namespace", Namespaced: false},
+						{Name: "nodes", Kind: "Node", Namespaced: false},
+						{Name: "pods", Kind: "Pod", Namespaced: true},
+						{Name: "secrets", Kind: "Secret", Namespaced: true},
+					},
+				},
+				{
+					GroupVersion: "apps/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "deployments", Kind: "Deployment", Namespaced: true},
+						{Name: "replicasets", Kind: "ReplicaSet", Namespaced: true},
+					},
+				},
+			},
+		},
+	}
+
+	resolver, err := NewResolverWithClients(dynamicClient, discoveryClient, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return resolver
+}
+
+func TestLookupNamespaceRestrictions(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	testcases := map[string]struct {
+		namespace   string
+		name        string
+		options     ResolveOptions
+		expectedNs  string
+		expectedErr string
+	}{
+		"in_namespaces": {
+			namespace:  "tenant-b",
+			name:       "config",
+			options:    ResolveOptions{LookupNamespaces: []string{"tenant-a", "tenant-b"}},
+			expectedNs: "tenant-b",
+		},
+		"single_namespace_default": {
+			name:       "config",
+			options:    ResolveOptions{LookupNamespaces: []string{"tenant-a"}},
+			expectedNs: "tenant-a",
+		},
+		"not_in_namespaces": {
+			namespace:   "other",
+			name:        "config",
+			options:     ResolveOptions{LookupNamespace: "tenant-a", LookupNamespaces: []string{"tenant-b"}},
+			expectedErr: "the namespace argument is restricted to tenant-a, tenant-b",
+		},
+		"multiple_namespaces_no_default": {
+			name:    "config",
+			options: ResolveOptions{LookupNamespaces: []string{"tenant-a", "tenant-b"}},
+			expectedErr: "the namespace argument is restricted to tenant-a, tenant-b: " +
+				"a namespace must be specified",
+		},
+		"selector_match": {
+			namespace:  "tenant-b",
+			name:       "config",
+			options:    ResolveOptions{LookupNamespaceSelector: "tenant=a"},
+			expectedNs: "tenant-b",
+		},
+		"selector_no_match": {
+			namespace:   "other",
+			name:        "config",
+			options:     ResolveOptions{LookupNamespaceSelector: "tenant=a"},
+			expectedErr: "the namespace argument is restricted to namespaces matching the label selector tenant=a",
+		},
+		"selector_missing_namespace": {
+			namespace:   "missing",
+			name:        "config",
+			options:     ResolveOptions{LookupNamespaceSelector: "tenant=a"},
+			expectedErr: "the namespace argument is restricted to namespaces matching the label selector tenant=a",
+		},
+		"selector_no_default": {
+			name:    "config",
+			options: ResolveOptions{LookupNamespace: "tenant-a", LookupNamespaceSelector: "tenant=a"},
+			expectedErr: "the namespace argument is restricted to tenant-a or namespaces matching the label " +
+				"selector tenant=a: a namespace must be specified",
+		},
+		"selector_invalid": {
+			namespace:   "other",
+			name:        "config",
+			options:     ResolveOptions{LookupNamespaceSelector: "tenant in (a"},
+			expectedErr: "the input is invalid: the lookup namespace selector is invalid",
+		},
+		"selector_list": {
+			namespace:  "tenant-a",
+			options:    ResolveOptions{LookupNamespaceSelector: "tenant=a"},
+			expectedNs: "tenant-a",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := resolver.lookup(&test.options, nil, "v1", "ConfigMap", test.namespace, test.name)
+			if test.expectedErr != "" {
+				if !errors.Is(err, ErrRestrictedNamespace) && !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("Expected a restricted namespace or invalid input error but got: %v", err)
+				}
+
+				if !strings.HasPrefix(err.Error(), test.expectedErr) {
+					t.Fatalf("Expected the error %q but got: %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if test.name == "" {
+				items, _, _ := unstructured.NestedSlice(result, "items")
+				if len(items) != 1 {
+					t.Fatalf("Expected one ConfigMap but got: %v", items)
+				}
+
+				result, _ = items[0].(map[string]interface{})
+			}
+
+			obj := unstructured.Unstructured{Object: result}
+			if obj.GetNamespace() != test.expectedNs || obj.GetName() != "config" {
+				t.Fatalf("Expected the ConfigMap %s/config but got: %v", test.expectedNs, result)
+			}
+		})
+	}
+}
+
+func TestLookupWithFieldSelector(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	// The subtests run in parallel after this function returns
+	t.Cleanup(cancelFunc)
+
+	cachingResolver, _, err := NewResolverWithCaching(ctx, k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	resolver, err := NewResolver(k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The lookup and the lookupWithFieldSelector calls with the same label selector are cached separately
+	tmpl := `
+all: '{{ len (lookup "v1" "ConfigMap" "testns" "" "app=test").items }}'
+byName: '{{ $cms := lookupWithFieldSelector "v1" "ConfigMap" "testns" "metadata.name=testcm-enva" }}
+  {{- (index $cms.items 0).data.cmkey1 }}'
+notByName: '{{ $cms := lookupWithFieldSelector "v1" "ConfigMap" "testns" "metadata.name!=testcm-enva" "app=test" }}
+  {{- len $cms.items }}'
+none: '{{ len (lookupWithFieldSelector "v1" "ConfigMap" "testns" "metadata.name=does-not-exist").items }}'
+schedulable: '{{ len (lookupWithFieldSelector "v1" "Node" "" "spec.unschedulable=false").items }}'
+`
+	expected := `{"all":"3","byName":"cmkey1Val","none":"0","notByName":"2","schedulable":"3"}`
+
+	testcases := map[string]*TemplateResolver{"caching": cachingResolver, "non_caching": resolver}
+
+	for testName, resolver := range testcases {
+		resolver := resolver
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			options := &ResolveOptions{
+				InputIsYAML: true,
+				Watcher: &client.ObjectIdentifier{
+					Version: "v1", Kind: "ConfigMap", Namespace: "testns", Name: "watcher-field-selector",
+				},
+			}
+
+			result, err := resolver.ResolveTemplate([]byte(tmpl), nil, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(result.ResolvedJSON) != expected {
+				t.Fatalf("Expected %s but got: %s", expected, result.ResolvedJSON)
+			}
+
+			invalidTmpl := `value: '{{ lookupWithFieldSelector "v1" "ConfigMap" "testns" "metadata.name" }}'`
+
+			_, err = resolver.ResolveTemplate([]byte(invalidTmpl), nil, options)
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("Expected an invalid input error but got: %v", err)
+			}
+
+			// The API server doesn't support this field for ConfigMaps, so it's rejected with and without caching
+			unsupportedTmpl := `value: '{{ lookupWithFieldSelector "v1" "ConfigMap" "testns" "data.cmkey1=value" }}'`
+
+			_, err = resolver.ResolveTemplate([]byte(unsupportedTmpl), nil, options)
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("Expected an invalid input error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestFilterByFieldSelector(t *testing.T) {
+	t.Parallel()
+
+	objects := []unstructured.Unstructured{
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "default"}}},
+		{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "set"},
+			"spec":     map[string]interface{}{"unschedulable": true, "nodeName": "node1"},
+			"status":   map[string]interface{}{"succeeded": int64(2)},
+		}},
+	}
+
+	nodeGVK := schema.GroupVersionKind{Version: "v1", Kind: "Node"}
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	jobGVK := schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}
+
+	testcases := map[string]struct {
+		gvk           schema.GroupVersionKind
+		fieldSelector string
+		expectedNames []string
+	}{
+		"missing_bool_default":   {nodeGVK, "spec.unschedulable=false", []string{"default"}},
+		"set_bool":               {nodeGVK, "spec.unschedulable=true", []string{"set"}},
+		"missing_string":         {podGVK, "spec.nodeName=", []string{"default"}},
+		"missing_string_not":     {podGVK, "spec.nodeName!=node1", []string{"default"}},
+		"missing_int_default":    {jobGVK, "status.successful=0", []string{"default"}},
+		"mapped_path":            {jobGVK, "status.successful=2", []string{"set"}},
+		"missing_bool_not_false": {podGVK, "spec.hostNetwork!=false", []string{}},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			filtered := filterByFieldSelector(objects, test.gvk, fields.ParseSelectorOrDie(test.fieldSelector))
+
+			names := make([]string, 0, len(filtered))
+			for _, obj := range filtered {
+				names = append(names, obj.GetName())
+			}
+
+			if !slices.Equal(names, test.expectedNames) {
+				t.Fatalf("Expected %v but got %v", test.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestLookupAcrossNamespaces(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	testcases := map[string]struct {
+		apiVersion         string
+		kind               string
+		namespaceSelector  string
+		name               string
+		options            ResolveOptions
+		expectedNamespaces []string
+		expectedErr        error
+	}{
+		"by_name": {
+			namespaceSelector:  "tenant=a",
+			name:               "config",
+			expectedNamespaces: []string{"tenant-a", "tenant-b"},
+		},
+		"list": {
+			namespaceSelector:  "tenant",
+			expectedNamespaces: []string{"other", "tenant-a", "tenant-b"},
+		},
+		"name_not_found": {
+			namespaceSelector:  "tenant=a",
+			name:               "does-not-exist",
+			expectedNamespaces: []string{},
+		},
+		"no_matching_namespaces": {
+			namespaceSelector:  "tenant=c",
+			name:               "config",
+			expectedNamespaces: []string{},
+		},
+		"restricted_namespaces_skipped": {
+			namespaceSelector:  "tenant",
+			name:               "config",
+			options:            ResolveOptions{LookupNamespaces: []string{"tenant-b", "other"}},
+			expectedNamespaces: []string{"other", "tenant-b"},
+		},
+		"restricted_namespace_selector": {
+			namespaceSelector:  "",
+			name:               "config",
+			options:            ResolveOptions{LookupNamespaceSelector: "tenant=b"},
+			expectedNamespaces: []string{"other"},
+		},
+		"restricted_namespaces_and_selector": {
+			namespaceSelector: "tenant",
+			name:              "config",
+			options: ResolveOptions{
+				LookupNamespace: "tenant-a", LookupNamespaces: []string{"missing"}, LookupNamespaceSelector: "tenant=b",
+			},
+			expectedNamespaces: []string{"other", "tenant-a"},
+		},
+		"invalid_selector": {
+			namespaceSelector: "tenant in (a",
+			expectedErr:       ErrInvalidInput,
+		},
+		"cluster_scoped": {
+			kind:              "Node",
+			namespaceSelector: "tenant",
+			expectedErr:       ErrInvalidInput,
+		},
+		"forbidden": {
+			namespaceSelector: "tenant=a",
+			name:              "forbidden",
+			expectedErr:       ErrLookupForbidden,
+		},
+		"forbidden_ignored": {
+			namespaceSelector:  "tenant=a",
+			name:               "forbidden",
+			options:            ResolveOptions{LookupIgnoreForbidden: true},
+			expectedNamespaces: []string{},
+		},
+		"missing_api": {
+			apiVersion:        "example.com/v1",
+			kind:              "Widget",
+			namespaceSelector: "tenant=a",
+			expectedErr:       ErrMissingAPIResource,
+		},
+		"missing_api_ignored": {
+			apiVersion:         "example.com/v1",
+			kind:               "Widget",
+			namespaceSelector:  "tenant=a",
+			options:            ResolveOptions{LookupIgnoreMissingAPIResource: true},
+			expectedNamespaces: []string{},
+		},
+		"strict_mode_not_found": {
+			namespaceSelector: "tenant=a",
+			name:              "does-not-exist",
+			options:           ResolveOptions{StrictMode: true},
+			expectedErr:       ErrObjectNotFound,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			apiVersion, kind := "v1", "ConfigMap"
+			if test.kind != "" {
+				apiVersion, kind = test.apiVersion, test.kind
+			}
+
+			if apiVersion == "" {
+				apiVersion = "v1"
+			}
+
+			result, err := resolver.lookupAcrossNamespaces(
+				&test.options, nil, apiVersion, kind, test.namespaceSelector, test.name,
+			)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resultList := unstructured.UnstructuredList{}
+			resultList.SetUnstructuredContent(result)
+
+			namespaces := []string{}
+			for _, item := range resultList.Items {
+				namespaces = append(namespaces, item.GetNamespace())
+			}
+
+			if !slices.Equal(namespaces, test.expectedNamespaces) {
+				t.Fatalf("Expected ConfigMaps from %v but got: %v", test.expectedNamespaces, namespaces)
+			}
+		})
+	}
+}
+
+func TestLookupAcrossNamespacesRestrictedQueries(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	_, err := resolver.lookupAcrossNamespaces(
+		&ResolveOptions{LookupNamespaces: []string{"tenant-a", "other"}}, nil, "v1", "ConfigMap", "tenant=a", "config",
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Only the allowed Namespaces and their ConfigMaps are queried
+	for _, action := range resolver.dynamicClient.(*dynamicfake.FakeDynamicClient).Actions() {
+		if action.GetVerb() == "list" {
+			t.Fatalf("Expected no list queries but got: %v", action)
+		}
+
+		if action.GetNamespace() != "" && action.GetNamespace() != "tenant-a" {
+			t.Fatalf("Expected no queries outside of tenant-a but got: %v", action)
+		}
+	}
+}
+
+func TestLookupClusterScopedPatterns(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	testcases := map[string]struct {
+		name          string
+		allowlist     []ClusterScopedObjectIdentifier
+		expectedNames []string
+		expectedErr   error
+	}{
+		"glob_match": {
+			name:          "tenant-b",
+			allowlist:     []ClusterScopedObjectIdentifier{{Kind: "Namespace", Name: "tenant-*"}},
+			expectedNames: []string{"tenant-b"},
+		},
+		"glob_no_match": {
+			name:        "other",
+			allowlist:   []ClusterScopedObjectIdentifier{{Kind: "Namespace", Name: "tenant-*"}},
+			expectedErr: ErrClusterScopedLookupRestricted,
+		},
+		"glob_list_not_allowed": {
+			allowlist:   []ClusterScopedObjectIdentifier{{Kind: "Namespace", Name: "tenant-*"}},
+			expectedErr: ErrClusterScopedLookupRestricted,
+		},
+		"glob_kind": {
+			name:          "other",
+			allowlist:     []ClusterScopedObjectIdentifier{{Group: "*", Kind: "Name*", Name: "*"}},
+			expectedNames: []string{"other"},
+		},
+		"regex_match": {
+			name:          "tenant-a",
+			allowlist:     []ClusterScopedObjectIdentifier{{Kind: "Namespace", NameRegex: "tenant-[ab]"}},
+			expectedNames: []string{"tenant-a"},
+		},
+		"regex_must_match_entire_name": {
+			name:        "tenant-a",
+			allowlist:   []ClusterScopedObjectIdentifier{{Kind: "Namespace", NameRegex: "tenant"}},
+			expectedErr: ErrClusterScopedLookupRestricted,
+		},
+		"regex_invalid": {
+			name:        "tenant-a",
+			allowlist:   []ClusterScopedObjectIdentifier{{Kind: "Namespace", NameRegex: "tenant-("}},
+			expectedErr: ErrInvalidInput,
+		},
+		"label_match": {
+			name:          "tenant-b",
+			allowlist:     []ClusterScopedObjectIdentifier{{Kind: "Namespace", Name: "*", LabelSelector: "tenant=a"}},
+			expectedNames: []string{"tenant-b"},
+		},
+		"label_no_match": {
+			name:        "other",
+			allowlist:   []ClusterScopedObjectIdentifier{{Kind: "Namespace", Name: "*", LabelSelector: "tenant=a"}},
+			expectedErr: ErrClusterScopedLookupRestricted,
+		},
+		"label_list_filtered": {
+			allowlist:     []ClusterScopedObjectIdentifier{{Kind: "Namespace", Name: "*", LabelSelector: "tenant=a"}},
+			expectedNames: []string{"tenant-a", "tenant-b"},
+		},
+		"label_list_unfiltered_by_other_entry": {
+			allowlist: []ClusterScopedObjectIdentifier{
+				{Kind: "Namespace", Name: "*", LabelSelector: "tenant=a"},
+				{Kind: "Namespace", Name: "*"},
+			},
+			expectedNames: []string{"other", "tenant-a", "tenant-b"},
+		},
+		"label_invalid": {
+			name:        "other",
+			allowlist:   []ClusterScopedObjectIdentifier{{Kind: "Namespace", Name: "*", LabelSelector: "tenant in (a"}},
+			expectedErr: ErrInvalidInput,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			options := &ResolveOptions{LookupNamespace: "tenant-a", ClusterScopedAllowList: test.allowlist}
+
+			result, err := resolver.lookup(options, nil, "v1", "Namespace", "", test.name)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			names := []string{}
+
+			if test.name != "" {
+				names = append(names, (&unstructured.Unstructured{Object: result}).GetName())
+			} else {
+				resultList := unstructured.UnstructuredList{}
+				resultList.SetUnstructuredContent(result)
+
+				for _, item := range resultList.Items {
+					names = append(names, item.GetName())
+				}
+
+				slices.Sort(names)
+			}
+
+			if !slices.Equal(names, test.expectedNames) {
+				t.Fatalf("Expected the namespaces %v but got: %v", test.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	testcases := map[string]struct {
+		kind            string
+		namespace       string
+		name            string
+		lookupNamespace string
+		expectedErr     error
+	}{
+		"forbidden":          {"ConfigMap", "testns", "forbidden", "", ErrLookupForbidden},
+		"timeout":            {"ConfigMap", "testns", "timeout", "", ErrLookupTimeout},
+		"restricted":         {"ConfigMap", "testns", "cm", "other", ErrRestrictedNamespace},
+		"cluster_restricted": {"Node", "", "node1", "testns", ErrClusterScopedLookupRestricted},
+		"missing_api":        {"NotAResource", "testns", "name", "", ErrMissingAPIResource},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			options := &ResolveOptions{LookupNamespace: test.lookupNamespace}
+
+			_, err := resolver.lookup(options, nil, "v1", test.kind, test.namespace, test.name)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			var lookupErr *LookupError
+			if !errors.As(err, &lookupErr) {
+				t.Fatalf("Expected a LookupError but got: %v", err)
+			}
+
+			if lookupErr.Kind != test.kind || lookupErr.Namespace != test.namespace || lookupErr.Name != test.name {
+				t.Fatalf("Unexpected object identifier in the LookupError: %+v", lookupErr)
+			}
+
+			// The errors also propagate through ResolveTemplate
+			tmpl := fmt.Sprintf(`value: '{{ lookup "v1" %q %q %q }}'`, test.kind, test.namespace, test.name)
+
+			_, err = resolver.ResolveTemplate([]byte(tmpl), nil, &ResolveOptions{
+				InputIsYAML: true, LookupNamespace: test.lookupNamespace,
+			})
+			if !errors.Is(err, test.expectedErr) || !errors.Is(err, ErrExecutionFailed) {
+				t.Fatalf("Expected the error %v from ResolveTemplate but got: %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLookupIgnoreErrors(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	testcases := map[string]struct {
+		inputTmpl        string
+		options          ResolveOptions
+		expectedResult   string
+		expectedWarnings []string
+		expectedErr      error
+	}{
+		"forbidden": {
+			inputTmpl:      `value: '{{ lookup "v1" "ConfigMap" "testns" "forbidden" | empty }}'`,
+			options:        ResolveOptions{LookupIgnoreForbidden: true},
+			expectedResult: `{"value":"true"}`,
+			expectedWarnings: []string{
+				`the lookup of v1 ConfigMap in namespace "testns" with name "forbidden" returned an empty result ` +
+					`because the lookup is forbidden`,
+			},
+		},
+		"forbidden_not_ignored": {
+			inputTmpl:   `value: '{{ (lookup "v1" "ConfigMap" "testns" "forbidden").data | len }}'`,
+			options:     ResolveOptions{LookupIgnoreMissingAPIResource: true},
+			expectedErr: ErrLookupForbidden,
+		},
+		"missing_api_resource": {
+			inputTmpl: `value: '{{ lookup "v1" "NotAResource" "testns" "name" | empty }}-` +
+				`{{ (lookup "v1" "NotAResource" "testns" "").items | len }}-` +
+				`{{ lookup "v1" "NotAResource" "testns" "name" | empty }}'`,
+			options:        ResolveOptions{LookupIgnoreMissingAPIResource: true},
+			expectedResult: `{"value":"true-0-true"}`,
+			expectedWarnings: []string{
+				`the lookup of v1 NotAResource in namespace "testns" with name "name" returned an empty result ` +
+					`because the API resource is not installed`,
+				`the lookup of v1 NotAResource in namespace "testns" with name "" returned an empty result ` +
+					`because the API resource is not installed`,
+			},
+		},
+		"missing_api_resource_not_ignored": {
+			inputTmpl:   `value: '{{ (lookup "v1" "NotAResource" "testns" "name").data | len }}'`,
+			options:     ResolveOptions{LookupIgnoreForbidden: true},
+			expectedErr: ErrMissingAPIResource,
+		},
+		"timeout_not_ignored": {
+			inputTmpl: `value: '{{ (lookup "v1" "ConfigMap" "testns" "timeout").data | len }}'`,
+			options: ResolveOptions{
+				LookupIgnoreForbidden: true, LookupIgnoreMissingAPIResource: true,
+			},
+			expectedErr: ErrLookupTimeout,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			test.options.InputIsYAML = true
+
+			result, err := resolver.ResolveTemplate([]byte(test.inputTmpl), nil, &test.options)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if string(result.ResolvedJSON) != test.expectedResult {
+				t.Fatalf("Expected the result %s but got: %s", test.expectedResult, string(result.ResolvedJSON))
+			}
+
+			if !slices.Equal(result.Warnings, test.expectedWarnings) {
+				t.Fatalf("Expected the warnings %v but got: %v", test.expectedWarnings, result.Warnings)
+			}
+		})
+	}
+}
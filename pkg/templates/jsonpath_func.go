@@ -0,0 +1,92 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// jsonPathQuery applies the input Kubernetes JSONPath expression (e.g. "{.items[*].metadata.name}") to the input data,
+// such as the result of the "lookup" template function. The curly braces are optional. If the expression can match
+// multiple values (e.g. it uses `[*]`, `..`, a filter, a union, a slice, or a range), a list of the matched values is
+// returned. Otherwise, the single matched value is returned. Missing keys result in an empty list or a nil value
+// unless allowMissingKeys is false.
+func jsonPathQuery(path string, data interface{}, allowMissingKeys bool) (interface{}, error) {
+	expression := path
+	if !strings.Contains(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	parsed, err := jsonpath.Parse("jsonpath", expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the JSONPath expression %s is invalid: %w", ErrInvalidInput, path, err)
+	}
+
+	jp := jsonpath.New("jsonpath").AllowMissingKeys(allowMissingKeys)
+
+	// Parse already succeeded above, so this won't fail
+	_ = jp.Parse(expression)
+
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to evaluate the JSONPath expression %s: %w", ErrInvalidInput, path, err)
+	}
+
+	values := []interface{}{}
+
+	for _, result := range results {
+		for _, value := range result {
+			if !value.IsValid() || !value.CanInterface() {
+				continue
+			}
+
+			values = append(values, value.Interface())
+		}
+	}
+
+	if len(parsed.Root.Nodes) > 1 || jsonPathMatchesMultiple(parsed.Root) {
+		return values, nil
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return values[0], nil
+}
+
+// jsonPathMatchesMultiple returns true if the parsed JSONPath expression can match multiple values.
+func jsonPathMatchesMultiple(node jsonpath.Node) bool {
+	switch typedNode := node.(type) {
+	case *jsonpath.ListNode:
+		for _, child := range typedNode.Nodes {
+			if jsonPathMatchesMultiple(child) {
+				return true
+			}
+		}
+
+		return false
+	case *jsonpath.ArrayNode:
+		// A single index (e.g. [0]) has a derived end index
+		return !typedNode.Params[1].Derived
+	case *jsonpath.WildcardNode, *jsonpath.RecursiveNode, *jsonpath.FilterNode, *jsonpath.UnionNode:
+		return true
+	case *jsonpath.IdentifierNode:
+		return typedNode.Name == "range"
+	default:
+		return false
+	}
+}
+
+// jsonPath applies the input JSONPath expression to the input data. Missing keys are ignored.
+func jsonPath(path string, data interface{}) (interface{}, error) {
+	return jsonPathQuery(path, data, true)
+}
+
+// jsonPathStrict is like jsonPath but returns an error when a key is missing. This is used in strict mode.
+func jsonPathStrict(path string, data interface{}) (interface{}, error) {
+	return jsonPathQuery(path, data, false)
+}
@@ -0,0 +1,103 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	testcases := map[string]struct {
+		inputTmpl      string
+		strictMode     bool
+		expectedResult string
+		expectedErr    error
+	}{
+		"list_wildcard": {
+			inputTmpl:      `value: '{{ lookup "v1" "Namespace" "" "" | jsonpath "{.items[*].metadata.name}" | len }}'`,
+			expectedResult: `{"value":"3"}`,
+		},
+		"scalar_without_braces": {
+			inputTmpl:      `value: '{{ lookup "v1" "Namespace" "" "tenant-a" | jsonpath ".metadata.labels.tenant" }}'`,
+			expectedResult: `{"value":"a"}`,
+		},
+		"single_index": {
+			inputTmpl: `value: '{{ $cms := lookup "v1" "ConfigMap" "tenant-b" "" }}` +
+				`{{ $cms | jsonpath "{.items[0].metadata.namespace}" }}'`,
+			expectedResult: `{"value":"tenant-b"}`,
+		},
+		"filter": {
+			inputTmpl: `value: '{{ lookup "v1" "Namespace" "" "" | ` +
+				`jsonpath "{.items[?(@.metadata.labels.tenant==\"b\")].metadata.name}" | toRawJson }}'`,
+			expectedResult: `{"value":"[\"other\"]"}`,
+		},
+		"filter_no_match": {
+			inputTmpl: `value: '{{ lookup "v1" "Namespace" "" "" | ` +
+				`jsonpath "{.items[?(@.metadata.labels.tenant==\"c\")].metadata.name}" | toRawJson }}'`,
+			expectedResult: `{"value":"[]"}`,
+		},
+		"map_result": {
+			inputTmpl: `value: '{{ (lookup "v1" "Namespace" "" "other" | ` +
+				`jsonpath "{.metadata.labels}").tenant }}'`,
+			expectedResult: `{"value":"b"}`,
+		},
+		"missing_key": {
+			inputTmpl: `value: '{{ lookup "v1" "Namespace" "" "other" | ` +
+				`jsonpath "{.metadata.annotations.missing}" | toRawJson }}'`,
+			expectedResult: `{"value":"null"}`,
+		},
+		"missing_key_strict": {
+			inputTmpl: `value: '{{ lookup "v1" "Namespace" "" "other" | ` +
+				`jsonpath "{.metadata.annotations.missing}" }}'`,
+			strictMode:  true,
+			expectedErr: ErrInvalidInput,
+		},
+		"invalid_expression": {
+			inputTmpl:   `value: '{{ lookup "v1" "Namespace" "" "" | jsonpath "{.items[*" }}'`,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := resolver.ResolveTemplate(
+				[]byte(test.inputTmpl), nil, &ResolveOptions{InputIsYAML: true, StrictMode: test.strictMode},
+			)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if string(result.ResolvedJSON) != test.expectedResult {
+				t.Fatalf("Expected %s but got: %s", test.expectedResult, result.ResolvedJSON)
+			}
+		})
+	}
+}
+
+func TestJSONPathInvalidExpressionMessage(t *testing.T) {
+	t.Parallel()
+
+	_, err := jsonPath("{.items[*", map[string]interface{}{})
+
+	expected := "the input is invalid: the JSONPath expression {.items[* is invalid: unterminated array"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected the error %q but got: %v", expected, err)
+	}
+}
@@ -27,7 +27,7 @@ func (t *TemplateResolver) fromSecret(
 ) (string, error) {
 	klog.V(2).Infof("fromSecret for namespace: %v, name: %v, key:%v", namespace, name, key)
 
-	if name == "" || (options.LookupNamespace == "" && namespace == "") || key == "" {
+	if name == "" || (namespace == "" && defaultLookupNamespace(options) == "") || key == "" {
 		return "", fmt.Errorf("%w: namespace, name, and key must be specified", ErrInvalidInput)
 	}
 
@@ -68,7 +68,7 @@ func (t *TemplateResolver) copySecretDataBase(
 ) (map[string]interface{}, error) {
 	klog.V(2).Infof("copySecretDataBase for namespace: %v, name: %v", namespace, name)
 
-	if name == "" || (options.LookupNamespace == "" && namespace == "") {
+	if name == "" || (namespace == "" && defaultLookupNamespace(options) == "") {
 		return nil, fmt.Errorf("%w: namespace and name must be specified", ErrInvalidInput)
 	}
 
@@ -155,7 +155,7 @@ func (t *TemplateResolver) fromConfigMap(
 ) (string, error) {
 	klog.V(2).Infof("fromConfigMap for namespace: %s, name: %s, key: %s", namespace, name, key)
 
-	if name == "" || (options.LookupNamespace == "" && namespace == "") || key == "" {
+	if name == "" || (namespace == "" && defaultLookupNamespace(options) == "") || key == "" {
 		return "", fmt.Errorf("%w: namespace, name, and key must be specified", ErrInvalidInput)
 	}
 
@@ -183,7 +183,7 @@ func (t *TemplateResolver) copyConfigMapData(
 ) (string, error) {
 	klog.V(2).Infof("copyConfigMapData for namespace: %s, name: %s", namespace, name)
 
-	if name == "" || (options.LookupNamespace == "" && namespace == "") {
+	if name == "" || (namespace == "" && defaultLookupNamespace(options) == "") {
 		return "", fmt.Errorf("%w: namespace and name must be specified", ErrInvalidInput)
 	}
 
@@ -216,3 +216,14 @@ func base64decode(v string) string {
 
 	return string(data)
 }
+
+// base64decodeStrict is the base64dec template function used in strict mode, which returns an error if the input is
+// not valid Base64 instead of returning the error text as the value.
+func base64decodeStrict(v string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,315 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// listItems returns the items of the input list. The input can be the result of a "lookup" call that lists objects
+// (i.e. a map with an "items" key) or a list, such as the result of another list function.
+func listItems(list interface{}) ([]interface{}, error) {
+	switch typedList := list.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case []interface{}:
+		return typedList, nil
+	case []map[string]interface{}:
+		items := make([]interface{}, 0, len(typedList))
+		for _, item := range typedList {
+			items = append(items, item)
+		}
+
+		return items, nil
+	case map[string]interface{}:
+		items, ok := typedList["items"]
+		if !ok {
+			return nil, fmt.Errorf("%w: expected a list or a lookup result with items", ErrInvalidInput)
+		}
+
+		if items == nil {
+			return []interface{}{}, nil
+		}
+
+		return listItems(items)
+	default:
+		return nil, fmt.Errorf("%w: expected a list or a lookup result with items but got %T", ErrInvalidInput, list)
+	}
+}
+
+// itemField returns the value of the input field in the item. The field is a dot-separated path (e.g.
+// "metadata.creationTimestamp") or a JSONPath expression in curly braces for keys that contain dots (e.g.
+// "{.metadata.labels.app\.kubernetes\.io/name}"). The boolean is false if the field is not set.
+func itemField(item interface{}, field string) (interface{}, bool, error) {
+	if strings.HasPrefix(field, "{") {
+		value, err := jsonPathQuery(field, item, true)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return value, value != nil, nil
+	}
+
+	typedItem, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("%w: expected the list items to be maps but got %T", ErrInvalidInput, item)
+	}
+
+	value, found, err := unstructured.NestedFieldNoCopy(typedItem, strings.Split(field, ".")...)
+	if err != nil || !found || value == nil {
+		return nil, false, nil //nolint:nilerr
+	}
+
+	return value, true, nil
+}
+
+// filterItems returns the items of the input list whose field equals the input value. The values are compared by their
+// string representation so that "3" matches 3.
+func filterItems(field string, value interface{}, list interface{}) ([]interface{}, error) {
+	items, err := listItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []interface{}{}
+
+	for _, item := range items {
+		itemValue, found, err := itemField(item, field)
+		if err != nil {
+			return nil, err
+		}
+
+		if found && fmt.Sprint(itemValue) == fmt.Sprint(value) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered, nil
+}
+
+// filterItemsByJSONPath returns the items of the input list for which the JSONPath expression returns a value other
+// than nil, false, an empty string, or an empty list (e.g. `{.status.conditions[?(@.type=="Ready")]}`).
+func filterItemsByJSONPath(expression string, list interface{}) ([]interface{}, error) {
+	items, err := listItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []interface{}{}
+
+	for _, item := range items {
+		value, err := jsonPathQuery(expression, item, true)
+		if err != nil {
+			return nil, err
+		}
+
+		switch typedValue := value.(type) {
+		case nil:
+			continue
+		case bool:
+			if !typedValue {
+				continue
+			}
+		case string:
+			if typedValue == "" {
+				continue
+			}
+		case []interface{}:
+			if len(typedValue) == 0 {
+				continue
+			}
+		}
+
+		filtered = append(filtered, item)
+	}
+
+	return filtered, nil
+}
+
+// sortItems returns the items of the input list sorted in ascending order by the input field. Numbers are compared
+// numerically and other values by their string representation, which works for RFC 3339 timestamps. Items without the
+// field are sorted first.
+func sortItems(field string, list interface{}) ([]interface{}, error) {
+	items, err := listItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[int]interface{}, len(items))
+	indexes := make([]int, 0, len(items))
+
+	for i, item := range items {
+		value, found, err := itemField(item, field)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			values[i] = value
+		}
+
+		indexes = append(indexes, i)
+	}
+
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		return compareValues(values[a], values[b])
+	})
+
+	sorted := make([]interface{}, 0, len(items))
+	for _, i := range indexes {
+		sorted = append(sorted, items[i])
+	}
+
+	return sorted, nil
+}
+
+// compareValues compares two field values for sorting. A nil value is less than any other value.
+func compareValues(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	aNumber, aIsNumber := toFloat64(a)
+	bNumber, bIsNumber := toFloat64(b)
+
+	if aIsNumber && bIsNumber {
+		return cmp.Compare(aNumber, bNumber)
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case int:
+		return float64(typedValue), true
+	case int32:
+		return float64(typedValue), true
+	case int64:
+		return float64(typedValue), true
+	case float32:
+		return float64(typedValue), true
+	case float64:
+		return typedValue, true
+	default:
+		return 0, false
+	}
+}
+
+// groupItemsByLabel returns a map of the values of the input label to the items of the input list with that label
+// value. Items without the label are omitted.
+func groupItemsByLabel(label string, list interface{}) (map[string]interface{}, error) {
+	items, err := listItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]interface{}{}
+
+	for _, item := range items {
+		value, found, err := itemField(item, "metadata.labels")
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			continue
+		}
+
+		itemLabels, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		labelValue, ok := itemLabels[label].(string)
+		if !ok {
+			continue
+		}
+
+		group, _ := groups[labelValue].([]interface{})
+		groups[labelValue] = append(group, item)
+	}
+
+	return groups, nil
+}
+
+// pluckItems returns the values of the input field from the items of the input list. Items without the field are
+// skipped.
+func pluckItems(field string, list interface{}) ([]interface{}, error) {
+	items, err := listItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{}
+
+	for _, item := range items {
+		value, found, err := itemField(item, field)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			values = append(values, value)
+		}
+	}
+
+	return values, nil
+}
+
+// dedupeItems returns the items of the input list without duplicates, keeping the first occurrence. Items are
+// duplicates if the input field has the same type and value, and items without the field are always kept. If the field
+// is an empty string, entire items are compared.
+func dedupeItems(field string, list interface{}) ([]interface{}, error) {
+	items, err := listItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	deduped := []interface{}{}
+	seenKeys := map[string]bool{}
+
+	for _, item := range items {
+		if field == "" {
+			if !slices.ContainsFunc(deduped, func(other interface{}) bool { return reflect.DeepEqual(item, other) }) {
+				deduped = append(deduped, item)
+			}
+
+			continue
+		}
+
+		value, found, err := itemField(item, field)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found || value == nil {
+			deduped = append(deduped, item)
+
+			continue
+		}
+
+		// Include the type so that values such as the string "3" and the number 3 aren't duplicates
+		key := fmt.Sprintf("%T:%v", value, value)
+		if seenKeys[key] {
+			continue
+		}
+
+		seenKeys[key] = true
+
+		deduped = append(deduped, item)
+	}
+
+	return deduped, nil
+}
@@ -0,0 +1,162 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestListFunctions(t *testing.T) {
+	t.Parallel()
+
+	// The context can't contain lists, so the list is passed as JSON
+	podsJSON := `{"items": [
+  {
+    "metadata": {
+      "name": "web-2",
+      "creationTimestamp": "2024-01-03T00:00:00Z",
+      "labels": {"app": "web", "app.kubernetes.io/name": "web"}
+    },
+    "status": {"phase": "Running", "restarts": 10, "conditions": [{"type": "Ready", "status": "True"}]}
+  },
+  {
+    "metadata": {
+      "name": "web-1",
+      "creationTimestamp": "2024-01-01T00:00:00Z",
+      "labels": {"app": "web", "app.kubernetes.io/name": "web"}
+    },
+    "status": {"phase": "Pending", "restarts": 2, "conditions": [{"type": "Ready", "status": "False"}]}
+  },
+  {
+    "metadata": {
+      "name": "db-1",
+      "creationTimestamp": "2024-01-02T00:00:00Z",
+      "labels": {"app": "db", "app.kubernetes.io/name": "db"}
+    },
+    "status": {"phase": "Running", "restarts": 3, "conditions": [{"type": "Ready", "status": "True"}]}
+  },
+  {
+    "metadata": {"name": "job-1", "creationTimestamp": "2024-01-04T00:00:00Z"},
+    "status": {"phase": "Succeeded", "restarts": 0, "conditions": [{"type": "Ready", "status": "False"}]}
+  }
+]}`
+
+	testcases := map[string]resolveTestCase{
+		"filterItems": {
+			inputTmpl: `value: '{{ $pods | filterItems "status.phase" "Running" | pluckItems "metadata.name" | ` +
+				`join "," }}'`,
+			expectedResult: `value: web-2,db-1`,
+		},
+		"filterItems_number": {
+			inputTmpl: `value: '{{ $pods | filterItems "status.restarts" "3" | pluckItems "metadata.name" | ` +
+				`join "," }}'`,
+			expectedResult: `value: db-1`,
+		},
+		"filterItemsByJSONPath": {
+			inputTmpl: `value: '{{ $pods | filterItemsByJSONPath "{.status.conditions[?(@.status==\"False\")]}" | ` +
+				`pluckItems "metadata.name" | join "," }}'`,
+			expectedResult: `value: web-1,job-1`,
+		},
+		"sortItems_timestamp": {
+			inputTmpl: `value: '{{ $pods | sortItems "metadata.creationTimestamp" | pluckItems "metadata.name" | ` +
+				`join "," }}'`,
+			expectedResult: `value: web-1,db-1,web-2,job-1`,
+		},
+		"sortItems_number": {
+			inputTmpl: `value: '{{ $pods | sortItems "status.restarts" | pluckItems "metadata.name" | ` +
+				`join "," }}'`,
+			expectedResult: `value: job-1,web-1,db-1,web-2`,
+		},
+		"sortItems_missing_first": {
+			inputTmpl: `value: '{{ $pods | sortItems "metadata.labels.app" | pluckItems "metadata.name" | ` +
+				`join "," }}'`,
+			expectedResult: `value: job-1,db-1,web-2,web-1`,
+		},
+		"groupItemsByLabel": {
+			inputTmpl: `{{ range $app, $pods := $pods | groupItemsByLabel "app.kubernetes.io/name" }}` +
+				`{{ $app }}: '{{ $pods | pluckItems "metadata.name" | join "," }}'
+{{ end }}`,
+			expectedResult: "db: db-1\nweb: web-2,web-1",
+		},
+		"pluckItems_jsonpath": {
+			inputTmpl: `value: '{{ $pods | pluckItems "{.metadata.labels.app\\.kubernetes\\.io/name}" | ` +
+				`join "," }}'`,
+			expectedResult: `value: web,web,db`,
+		},
+		"dedupeItems_field": {
+			inputTmpl: `value: '{{ $pods | dedupeItems "metadata.labels.app" | pluckItems "metadata.name" | ` +
+				`join "," }}'`,
+			expectedResult: `value: web-2,db-1,job-1`,
+		},
+		"dedupeItems_missing_field": {
+			inputTmpl: `value: '{{ $pods | dedupeItems "metadata.labels.missing" | pluckItems "metadata.name" | ` +
+				`join "," }}'`,
+			expectedResult: `value: web-2,web-1,db-1,job-1`,
+		},
+		"dedupeItems_field_type": {
+			inputTmpl: `value: '{{ range $i, $v := list (dict "v" "3") (dict "v" 3) (dict "v" 3) (dict "v" "3") | ` +
+				`dedupeItems "v" | pluckItems "v" }}{{ if $i }},{{ end }}{{ printf "%T" $v }}{{ end }}'`,
+			expectedResult: `value: string,int`,
+		},
+		"dedupeItems_values": {
+			inputTmpl:      `value: '{{ $pods | pluckItems "status.phase" | dedupeItems "" | join "," }}'`,
+			expectedResult: `value: Running,Pending,Succeeded`,
+		},
+		"range_over_result": {
+			inputTmpl: `{{ range $pods | filterItems "metadata.labels.app" "db" }}value: '{{ .metadata.name }}'` +
+				`{{ end }}`,
+			expectedResult: `value: db-1`,
+		},
+		"invalid_list": {
+			inputTmpl:   `value: '{{ "not a list" | filterItems "status.phase" "Running" }}'`,
+			expectedErr: ErrInvalidInput,
+		},
+		"invalid_items": {
+			inputTmpl:   `value: '{{ list "a" "b" | sortItems "metadata.name" }}'`,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			test.inputTmpl = `{{ $pods := fromJson .Pods }}` + test.inputTmpl
+			test.ctx = struct{ Pods string }{podsJSON}
+			test.resolveOptions.InputIsYAML = true
+
+			doResolveTest(t, test)
+		})
+	}
+}
+
+func TestListFunctionsLookup(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t)
+
+	tmpl := `value: '{{ lookup "v1" "Namespace" "" "" | filterItems "metadata.labels.tenant" "a" | ` +
+		`sortItems "metadata.name" | pluckItems "metadata.name" | join "," }}'`
+
+	result, err := resolver.ResolveTemplate([]byte(tmpl), nil, &ResolveOptions{InputIsYAML: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"value":"tenant-a,tenant-b"}`
+	if string(result.ResolvedJSON) != expected {
+		t.Fatalf("Expected %s but got: %s", expected, result.ResolvedJSON)
+	}
+
+	_, err = resolver.ResolveTemplate(
+		[]byte(`value: '{{ lookup "v1" "Namespace" "" "other" | filterItems "metadata.name" "other" }}'`),
+		nil,
+		&ResolveOptions{InputIsYAML: true},
+	)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error for a lookup of a single object but got: %v", err)
+	}
+}
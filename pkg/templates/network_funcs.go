@@ -0,0 +1,171 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// maxCIDRSubnetBits limits cidrSubnets to 4096 subnets to avoid generating huge lists by mistake.
+const maxCIDRSubnetBits = 12
+
+// parseIP parses an IPv4 or IPv6 address. IPv4-mapped IPv6 addresses (e.g. "::ffff:10.0.0.1") are converted to IPv4.
+func parseIP(ip string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("%w: the IP address %s is invalid", ErrInvalidInput, ip)
+	}
+
+	return addr.Unmap(), nil
+}
+
+// parseCIDR parses a CIDR (e.g. "10.0.0.0/16") and returns it with the host bits cleared.
+func parseCIDR(cidr string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: the CIDR %s is invalid", ErrInvalidInput, cidr)
+	}
+
+	if prefix.Addr().Is4In6() {
+		bits := prefix.Bits() - 96
+		if bits < 0 {
+			return netip.Prefix{}, fmt.Errorf("%w: the CIDR %s is invalid", ErrInvalidInput, cidr)
+		}
+
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), bits)
+	}
+
+	return prefix.Masked(), nil
+}
+
+// addToAddr returns the address that is offset from the input address. The boolean is false if the result overflows
+// the address family.
+func addToAddr(addr netip.Addr, offset *big.Int) (netip.Addr, bool) {
+	addrBytes := addr.AsSlice()
+
+	result := new(big.Int).SetBytes(addrBytes)
+	result.Add(result, offset)
+
+	if result.Sign() < 0 || result.BitLen() > len(addrBytes)*8 {
+		return netip.Addr{}, false
+	}
+
+	resultAddr, _ := netip.AddrFromSlice(result.FillBytes(make([]byte, len(addrBytes))))
+
+	return resultAddr, true
+}
+
+// ipInCIDR returns true if the input IP address is in the input CIDR.
+func ipInCIDR(cidr string, ip string) (bool, error) {
+	prefix, err := parseCIDR(cidr)
+	if err != nil {
+		return false, err
+	}
+
+	addr, err := parseIP(ip)
+	if err != nil {
+		return false, err
+	}
+
+	return prefix.Contains(addr), nil
+}
+
+// cidrSubnets returns all the subnets with the input prefix length in the input CIDR (e.g. the /24 subnets of a /22).
+func cidrSubnets(prefixLength int, cidr string) ([]string, error) {
+	prefix, err := parseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefixLength < prefix.Bits() || prefixLength > prefix.Addr().BitLen() {
+		return nil, fmt.Errorf(
+			"%w: the prefix length must be between %d and %d for the CIDR %s",
+			ErrInvalidInput, prefix.Bits(), prefix.Addr().BitLen(), cidr,
+		)
+	}
+
+	if prefixLength-prefix.Bits() > maxCIDRSubnetBits {
+		return nil, fmt.Errorf(
+			"%w: the CIDR %s has more than %d subnets with the prefix length %d",
+			ErrInvalidInput, cidr, 1<<maxCIDRSubnetBits, prefixLength,
+		)
+	}
+
+	count := 1 << (prefixLength - prefix.Bits())
+	subnetSize := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefixLength))
+	subnets := make([]string, 0, count)
+	addr := prefix.Addr()
+
+	for i := 0; i < count; i++ {
+		subnets = append(subnets, netip.PrefixFrom(addr, prefixLength).String())
+
+		// The address after the last subnet can overflow (e.g. 255.255.255.255/32), but it isn't used
+		addr, _ = addToAddr(addr, subnetSize)
+	}
+
+	return subnets, nil
+}
+
+// cidrHost returns the Nth address in the input CIDR, where 0 is the network address. A negative number counts back
+// from the last address in the CIDR, so -1 is the last address (e.g. the IPv4 broadcast address).
+func cidrHost(hostNumber int, cidr string) (string, error) {
+	prefix, err := parseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+
+	size := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+
+	offset := big.NewInt(int64(hostNumber))
+	if hostNumber < 0 {
+		offset.Add(offset, size)
+	}
+
+	if offset.Sign() < 0 || offset.Cmp(size) >= 0 {
+		return "", fmt.Errorf("%w: the CIDR %s doesn't have a host number %d", ErrInvalidInput, cidr, hostNumber)
+	}
+
+	addr, _ := addToAddr(prefix.Addr(), offset)
+
+	return addr.String(), nil
+}
+
+// cidrsOverlap returns true if the two input CIDRs share any addresses.
+func cidrsOverlap(cidr1 string, cidr2 string) (bool, error) {
+	prefix1, err := parseCIDR(cidr1)
+	if err != nil {
+		return false, err
+	}
+
+	prefix2, err := parseCIDR(cidr2)
+	if err != nil {
+		return false, err
+	}
+
+	return prefix1.Overlaps(prefix2), nil
+}
+
+// normalizeIP returns the canonical form of the input IP address. IPv6 addresses are lowercase with the longest run of
+// zeros compressed (e.g. "2001:DB8:0:0::1" is "2001:db8::1") and IPv4-mapped IPv6 addresses are converted to IPv4.
+func normalizeIP(ip string) (string, error) {
+	addr, err := parseIP(ip)
+	if err != nil {
+		return "", err
+	}
+
+	return addr.String(), nil
+}
+
+// normalizeCIDR returns the canonical form of the input CIDR with the host bits cleared (e.g. "10.1.2.3/16" is
+// "10.1.0.0/16"). The address is normalized the same way as normalizeIP.
+func normalizeCIDR(cidr string) (string, error) {
+	prefix, err := parseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix.String(), nil
+}
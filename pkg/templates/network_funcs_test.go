@@ -0,0 +1,213 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestIPInCIDRAndOverlaps(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		fn          func(string, string) (bool, error)
+		a           string
+		b           string
+		expected    bool
+		expectedErr error
+	}{
+		"ip_in_cidr":           {ipInCIDR, "10.128.0.0/14", "10.130.1.2", true, nil},
+		"ip_not_in_cidr":       {ipInCIDR, "10.128.0.0/14", "10.132.0.1", false, nil},
+		"ip_in_host_bits_cidr": {ipInCIDR, "10.128.5.6/14", "10.128.0.1", true, nil},
+		"ipv6_in_cidr":         {ipInCIDR, "fd01::/48", "FD01:0:0:1::5", true, nil},
+		"ipv4_mapped_in_cidr":  {ipInCIDR, "10.0.0.0/8", "::ffff:10.0.0.1", true, nil},
+		"ipv4_in_ipv6_cidr":    {ipInCIDR, "fd01::/48", "10.0.0.1", false, nil},
+		"invalid_ip":           {ipInCIDR, "10.0.0.0/8", "10.0.0.256", false, ErrInvalidInput},
+		"invalid_cidr":         {ipInCIDR, "10.0.0.0", "10.0.0.1", false, ErrInvalidInput},
+		"overlap_contained":    {cidrsOverlap, "10.0.0.0/16", "10.0.128.0/17", true, nil},
+		"overlap_same":         {cidrsOverlap, "172.30.0.0/16", "172.30.0.0/16", true, nil},
+		"no_overlap_adjacent":  {cidrsOverlap, "10.0.0.0/17", "10.0.128.0/17", false, nil},
+		"no_overlap_families":  {cidrsOverlap, "10.0.0.0/8", "fd01::/48", false, nil},
+		"overlap_ipv4_mapped":  {cidrsOverlap, "::ffff:10.0.0.0/104", "10.1.0.0/16", true, nil},
+		"overlap_invalid":      {cidrsOverlap, "10.0.0.0/8", "fd01::/129", false, ErrInvalidInput},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := test.fn(test.a, test.b)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			if value != test.expected {
+				t.Fatalf("Expected %v but got %v", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestCIDRSubnets(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		prefixLength int
+		cidr         string
+		expected     []string
+		expectedErr  error
+	}{
+		"ipv4": {
+			24, "10.0.0.0/22", []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}, nil,
+		},
+		"same_length": {16, "10.1.2.3/16", []string{"10.1.0.0/16"}, nil},
+		"end_of_range": {
+			32, "255.255.255.254/31", []string{"255.255.255.254/32", "255.255.255.255/32"}, nil,
+		},
+		"ipv6":          {64, "fd01:0:0:a::/63", []string{"fd01:0:0:a::/64", "fd01:0:0:b::/64"}, nil},
+		"shorter":       {8, "10.0.0.0/16", nil, ErrInvalidInput},
+		"too_long":      {33, "10.0.0.0/16", nil, ErrInvalidInput},
+		"too_many":      {29, "10.0.0.0/16", nil, ErrInvalidInput},
+		"invalid_cidr":  {24, "10.0.0.0/33", nil, ErrInvalidInput},
+		"max_allowed":   {28, "10.0.0.0/16", nil, nil},
+		"ipv6_too_many": {64, "fd01::/48", nil, ErrInvalidInput},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			subnets, err := cidrSubnets(test.prefixLength, test.cidr)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			if testName == "max_allowed" {
+				if len(subnets) != 4096 || subnets[4095] != "10.0.255.240/28" {
+					t.Fatalf("Expected 4096 subnets ending with 10.0.255.240/28 but got %d", len(subnets))
+				}
+
+				return
+			}
+
+			if !reflect.DeepEqual(subnets, test.expected) {
+				t.Fatalf("Expected %v but got %v", test.expected, subnets)
+			}
+		})
+	}
+}
+
+func TestCIDRHostAndNormalize(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		fn          func() (string, error)
+		expected    string
+		expectedErr error
+	}{
+		"host_network":   {func() (string, error) { return cidrHost(0, "10.0.0.0/24") }, "10.0.0.0", nil},
+		"host_first":     {func() (string, error) { return cidrHost(1, "10.0.0.0/24") }, "10.0.0.1", nil},
+		"host_carry":     {func() (string, error) { return cidrHost(300, "10.0.0.0/16") }, "10.0.1.44", nil},
+		"host_last":      {func() (string, error) { return cidrHost(-1, "10.0.0.0/24") }, "10.0.0.255", nil},
+		"host_from_end":  {func() (string, error) { return cidrHost(-2, "10.0.0.0/24") }, "10.0.0.254", nil},
+		"host_ipv6":      {func() (string, error) { return cidrHost(10, "fd01::/64") }, "fd01::a", nil},
+		"host_ipv6_last": {func() (string, error) { return cidrHost(-1, "fd01::/120") }, "fd01::ff", nil},
+		"host_too_big":   {func() (string, error) { return cidrHost(256, "10.0.0.0/24") }, "", ErrInvalidInput},
+		"host_too_small": {func() (string, error) { return cidrHost(-257, "10.0.0.0/24") }, "", ErrInvalidInput},
+		"ip_ipv6":        {func() (string, error) { return normalizeIP("2001:DB8:0:0:0:0:0:1") }, "2001:db8::1", nil},
+		"ip_ipv4_mapped": {func() (string, error) { return normalizeIP("::ffff:192.168.1.1") }, "192.168.1.1", nil},
+		"ip_whitespace":  {func() (string, error) { return normalizeIP(" 10.0.0.1\n") }, "10.0.0.1", nil},
+		"ip_invalid":     {func() (string, error) { return normalizeIP("10.0.0") }, "", ErrInvalidInput},
+		"cidr_host_bits": {func() (string, error) { return normalizeCIDR("10.1.2.3/16") }, "10.1.0.0/16", nil},
+		"cidr_ipv6": {
+			func() (string, error) { return normalizeCIDR("FD01:0:0:A::1/64") }, "fd01:0:0:a::/64", nil,
+		},
+		"cidr_ipv4_mapped": {
+			func() (string, error) { return normalizeCIDR("::ffff:10.1.0.0/112") }, "10.1.0.0/16", nil,
+		},
+		"cidr_invalid": {
+			func() (string, error) { return normalizeCIDR("::ffff:10.1.0.0/64") }, "", ErrInvalidInput,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := test.fn()
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			if value != test.expected {
+				t.Fatalf("Expected %q but got %q", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestNetworkFunctionsTemplate(t *testing.T) {
+	t.Parallel()
+
+	doResolveTest(t, resolveTestCase{
+		inputTmpl: `subnets: '{{ .Network | cidrSubnets 24 | join "," }}'
+gateway: '{{ .Network | cidrHost 1 }}'
+internal: '{{ "10.0.2.5" | ipInCIDR .Network }}'
+overlaps: '{{ cidrsOverlap .Network "10.0.3.0/24" }}'`,
+		ctx:            struct{ Network string }{"10.0.0.0/23"},
+		resolveOptions: ResolveOptions{InputIsYAML: true},
+		expectedResult: "gateway: 10.0.0.1\ninternal: \"false\"\noverlaps: \"false\"\nsubnets: 10.0.0.0/24,10.0.1.0/24",
+	})
+}
+
+func TestNetworkFunctionsREADMEExamples(t *testing.T) {
+	t.Parallel()
+
+	// The examples in the README must run as documented
+	testcases := map[string]resolveTestCase{
+		"ipInCIDR": {
+			inputTmpl:      `value: '{{ if "10.128.0.5" | ipInCIDR "10.128.0.0/14" }}internal{{ end }}'`,
+			expectedResult: "value: internal",
+		},
+		"cidrSubnets": {
+			inputTmpl:      `value: '{{ "10.0.0.0/22" | cidrSubnets 24 | join "," }}'`,
+			expectedResult: "value: 10.0.0.0/24,10.0.1.0/24,10.0.2.0/24,10.0.3.0/24",
+		},
+		"cidrHost": {
+			inputTmpl:      `value: '{{ "10.0.0.0/24" | cidrHost 1 }}'`,
+			expectedResult: "value: 10.0.0.1",
+		},
+		"cidrsOverlap": {
+			inputTmpl:      `value: '{{ cidrsOverlap "10.0.0.0/16" "10.0.128.0/17" }}'`,
+			expectedResult: `value: "true"`,
+		},
+		"normalizeIP": {
+			inputTmpl:      `value: '{{ normalizeIP "2001:DB8:0:0:0:0:0:1" }}'`,
+			expectedResult: `value: 2001:db8::1`,
+		},
+		"normalizeCIDR": {
+			inputTmpl:      `value: '{{ normalizeCIDR "10.1.2.3/16" }}'`,
+			expectedResult: "value: 10.1.0.0/16",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			test.resolveOptions = ResolveOptions{InputIsYAML: true}
+
+			doResolveTest(t, test)
+		})
+	}
+}
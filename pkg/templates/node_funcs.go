@@ -0,0 +1,276 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func (t *TemplateResolver) getNodesHelper(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+) func(...string) (map[string]interface{}, error) {
+	return func(labelSelector ...string) (map[string]interface{}, error) {
+		return t.getNodes(options, templateResult, labelSelector...)
+	}
+}
+
+// getNodes returns a list of the nodes matching the input label selectors. All nodes are returned if no label selector
+// is provided.
+func (t *TemplateResolver) getNodes(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+	labelSelector ...string,
+) (map[string]interface{}, error) {
+	return t.getOrList(options, templateResult, "v1", "Node", "", "", labelSelector...)
+}
+
+// nodeItems returns the nodes of the input list as Unstructured objects. The input can be the result of "getNodes",
+// "lookup", or a list function.
+func nodeItems(list interface{}) ([]*unstructured.Unstructured, error) {
+	items, err := listItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*unstructured.Unstructured, 0, len(items))
+
+	for _, item := range items {
+		node, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: expected the list items to be nodes but got %T", ErrInvalidInput, item)
+		}
+
+		nodes = append(nodes, &unstructured.Unstructured{Object: node})
+	}
+
+	return nodes, nil
+}
+
+// hasTaint returns true if the node has a taint matching the input taint in the kubectl format of key[=value][:effect].
+// The value and effect are only compared if they are specified.
+func hasTaint(node *unstructured.Unstructured, taint string) bool {
+	key, effect, hasEffect := strings.Cut(taint, ":")
+	key, value, hasValue := strings.Cut(key, "=")
+
+	taints, _, _ := unstructured.NestedSlice(node.Object, "spec", "taints")
+
+	for _, nodeTaint := range taints {
+		typedTaint, ok := nodeTaint.(map[string]interface{})
+		if !ok || typedTaint["key"] != key {
+			continue
+		}
+
+		if hasValue {
+			// An empty value is omitted from the taint
+			taintValue, _ := typedTaint["value"].(string)
+			if taintValue != value {
+				continue
+			}
+		}
+
+		if hasEffect && typedTaint["effect"] != effect {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// filterNodesByTaint returns the nodes of the input list that have (or don't have if withTaint is false) a taint
+// matching any of the input taints.
+func filterNodesByTaint(taints []string, withTaint bool, list interface{}) ([]interface{}, error) {
+	if len(taints) == 0 {
+		return nil, fmt.Errorf("%w: at least one taint must be specified", ErrInvalidInput)
+	}
+
+	nodes, err := nodeItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []interface{}{}
+
+	for _, node := range nodes {
+		tainted := slices.ContainsFunc(taints, func(taint string) bool { return hasTaint(node, taint) })
+
+		if tainted == withTaint {
+			filtered = append(filtered, node.Object)
+		}
+	}
+
+	return filtered, nil
+}
+
+// nodesWithTaint returns the nodes of the input list with a taint matching the input taint in the kubectl format of
+// key[=value][:effect] (e.g. "node-role.kubernetes.io/infra:NoSchedule"). The list is the last argument so that it
+// can be piped to the function, and multiple taints can be specified before it to match any of them.
+func nodesWithTaint(args ...interface{}) ([]interface{}, error) {
+	taints, list, err := taintArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterNodesByTaint(taints, true, list)
+}
+
+// nodesWithoutTaint returns the nodes of the input list without a taint matching any of the input taints. See
+// nodesWithTaint for the taint format.
+func nodesWithoutTaint(args ...interface{}) ([]interface{}, error) {
+	taints, list, err := taintArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterNodesByTaint(taints, false, list)
+}
+
+// taintArgs splits the arguments of nodesWithTaint and nodesWithoutTaint into the taints and the node list.
+func taintArgs(args []interface{}) ([]string, interface{}, error) {
+	if len(args) < 2 {
+		return nil, nil, fmt.Errorf("%w: a taint and a list of nodes must be specified", ErrInvalidInput)
+	}
+
+	taints := make([]string, 0, len(args)-1)
+
+	for _, arg := range args[:len(args)-1] {
+		taint, ok := arg.(string)
+		if !ok || taint == "" {
+			return nil, nil, fmt.Errorf("%w: the taint must be a non-empty string but got %v", ErrInvalidInput, arg)
+		}
+
+		taints = append(taints, taint)
+	}
+
+	return taints, args[len(args)-1], nil
+}
+
+// nodeCount returns the number of nodes in the input list.
+func nodeCount(list interface{}) (int, error) {
+	nodes, err := nodeItems(list)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(nodes), nil
+}
+
+// sumNodeResource returns the sum of the input resource (e.g. "cpu" or "memory") in the input status field (i.e.
+// "capacity" or "allocatable") of the nodes in the input list. Nodes that don't report the resource are skipped.
+func sumNodeResource(field string, resourceName string, list interface{}) (string, error) {
+	nodes, err := nodeItems(list)
+	if err != nil {
+		return "", err
+	}
+
+	total := resource.Quantity{}
+
+	for _, node := range nodes {
+		value, found, _ := unstructured.NestedString(node.Object, "status", field, resourceName)
+		if !found {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return "", fmt.Errorf(
+				"%w: the %s %s of the node %s is invalid: %w",
+				ErrInvalidInput, resourceName, field, node.GetName(), err,
+			)
+		}
+
+		total.Add(quantity)
+	}
+
+	return total.String(), nil
+}
+
+// nodeCapacity returns the total capacity of the input resource (e.g. "cpu" or "memory") of the nodes in the input
+// list as a resource quantity.
+func nodeCapacity(resourceName string, list interface{}) (string, error) {
+	return sumNodeResource("capacity", resourceName, list)
+}
+
+// nodeAllocatable returns the total allocatable amount of the input resource (e.g. "cpu" or "memory") of the nodes in
+// the input list as a resource quantity.
+func nodeAllocatable(resourceName string, list interface{}) (string, error) {
+	return sumNodeResource("allocatable", resourceName, list)
+}
+
+// nodeLabelValues returns the sorted unique values of the input label on the nodes in the input list.
+func nodeLabelValues(label string, list interface{}) ([]string, error) {
+	nodes, err := nodeItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []string{}
+
+	for _, node := range nodes {
+		value, ok := node.GetLabels()[label]
+		if ok && value != "" && !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+
+	slices.Sort(values)
+
+	return values, nil
+}
+
+// nodeZones returns the sorted unique zones of the nodes in the input list from the topology.kubernetes.io/zone label.
+func nodeZones(list interface{}) ([]string, error) {
+	return nodeLabelValues("topology.kubernetes.io/zone", list)
+}
+
+// nodeRegions returns the sorted unique regions of the nodes in the input list from the topology.kubernetes.io/region
+// label.
+func nodeRegions(list interface{}) ([]string, error) {
+	return nodeLabelValues("topology.kubernetes.io/region", list)
+}
+
+// countNodesBy returns a map of the values of the input label to the number of nodes in the input list with that value.
+// If a node doesn't have the label, the input status.nodeInfo field is used instead.
+func countNodesBy(label string, nodeInfoField string, list interface{}) (map[string]interface{}, error) {
+	nodes, err := nodeItems(list)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]interface{}{}
+
+	for _, node := range nodes {
+		value := node.GetLabels()[label]
+		if value == "" {
+			value, _, _ = unstructured.NestedString(node.Object, "status", "nodeInfo", nodeInfoField)
+		}
+
+		if value == "" {
+			continue
+		}
+
+		count, _ := counts[value].(int)
+		counts[value] = count + 1
+	}
+
+	return counts, nil
+}
+
+// nodeArchitectures returns a map of CPU architectures (e.g. "amd64") to the number of nodes in the input list with
+// that architecture.
+func nodeArchitectures(list interface{}) (map[string]interface{}, error) {
+	return countNodesBy("kubernetes.io/arch", "architecture", list)
+}
+
+// nodeOperatingSystems returns a map of operating systems (e.g. "linux") to the number of nodes in the input list with
+// that operating system.
+func nodeOperatingSystems(list interface{}) (map[string]interface{}, error) {
+	return countNodesBy("kubernetes.io/os", "operatingSystem", list)
+}
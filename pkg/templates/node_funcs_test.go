@@ -0,0 +1,216 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testNodesJSON is a Node list used by the node function tests. The Nodes have the zone, region, and architecture
+// labels, taints, and resources that the functions aggregate.
+const testNodesJSON = `{"items": [
+  {
+    "apiVersion": "v1",
+    "kind": "Node",
+    "metadata": {
+      "name": "worker-1",
+      "labels": {
+        "kubernetes.io/arch": "arm64",
+        "topology.kubernetes.io/zone": "us-east-1b",
+        "topology.kubernetes.io/region": "us-east-1"
+      }
+    },
+    "status": {
+      "capacity": {"cpu": "4", "memory": "16Gi"},
+      "allocatable": {"cpu": "4", "memory": "1Gi"},
+      "nodeInfo": {"architecture": "amd64", "operatingSystem": "linux"}
+    }
+  },
+  {
+    "apiVersion": "v1",
+    "kind": "Node",
+    "metadata": {
+      "name": "worker-2",
+      "labels": {"topology.kubernetes.io/zone": "us-east-1a", "topology.kubernetes.io/region": "us-east-1"}
+    },
+    "spec": {"taints": [{"key": "dedicated", "value": "gpu", "effect": "NoSchedule"}]},
+    "status": {
+      "capacity": {"cpu": "8", "memory": "32Gi"},
+      "allocatable": {"cpu": "8", "memory": "1Gi"},
+      "nodeInfo": {"architecture": "amd64", "operatingSystem": "linux"}
+    }
+  },
+  {
+    "apiVersion": "v1",
+    "kind": "Node",
+    "metadata": {"name": "infra-1", "labels": {"topology.kubernetes.io/zone": "us-east-1a"}},
+    "spec": {"taints": [{"key": "node-role.kubernetes.io/infra", "effect": "NoExecute"}]},
+    "status": {
+      "capacity": {"cpu": "500m", "memory": "8Gi"},
+      "allocatable": {"cpu": "500m", "memory": "1Gi"},
+      "nodeInfo": {"architecture": "amd64", "operatingSystem": "linux"}
+    }
+  }
+]}`
+
+func TestNodeTaintFunctions(t *testing.T) {
+	t.Parallel()
+
+	nodes := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(testNodesJSON), &nodes); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := map[string]struct {
+		fn            func(...interface{}) ([]interface{}, error)
+		taints        []interface{}
+		expectedNames []string
+	}{
+		"key":              {nodesWithTaint, []interface{}{"dedicated"}, []string{"worker-2"}},
+		"key_value":        {nodesWithTaint, []interface{}{"dedicated=gpu"}, []string{"worker-2"}},
+		"key_value_effect": {nodesWithTaint, []interface{}{"dedicated=gpu:NoSchedule"}, []string{"worker-2"}},
+		"wrong_value":      {nodesWithTaint, []interface{}{"dedicated=fpga"}, []string{}},
+		"wrong_effect":     {nodesWithTaint, []interface{}{"dedicated:NoExecute"}, []string{}},
+		"empty_value": {
+			nodesWithTaint, []interface{}{"node-role.kubernetes.io/infra=:NoExecute"}, []string{"infra-1"},
+		},
+		"multiple": {
+			nodesWithTaint,
+			[]interface{}{"dedicated", "node-role.kubernetes.io/infra"},
+			[]string{"worker-2", "infra-1"},
+		},
+		"without": {nodesWithoutTaint, []interface{}{"dedicated"}, []string{"worker-1", "infra-1"}},
+		"without_multiple": {
+			nodesWithoutTaint, []interface{}{"dedicated", "node-role.kubernetes.io/infra"}, []string{"worker-1"},
+		},
+		"without_wrong_effect": {
+			nodesWithoutTaint, []interface{}{"dedicated:NoExecute"}, []string{"worker-1", "worker-2", "infra-1"},
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			filtered, err := test.fn(append(test.taints, nodes)...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			names, err := pluckItems("metadata.name", filtered)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(names) != len(test.expectedNames) {
+				t.Fatalf("Expected %v but got %v", test.expectedNames, names)
+			}
+
+			for i, name := range names {
+				if name != test.expectedNames[i] {
+					t.Fatalf("Expected %v but got %v", test.expectedNames, names)
+				}
+			}
+		})
+	}
+
+	if _, err := nodesWithTaint(nodes); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error without a taint but got: %v", err)
+	}
+
+	if _, err := nodesWithoutTaint("", nodes); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error with an empty taint but got: %v", err)
+	}
+}
+
+func TestNodeAggregationFunctions(t *testing.T) {
+	t.Parallel()
+
+	nodes := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(testNodesJSON), &nodes); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := nodeCount(nodes)
+	if err != nil || count != 3 {
+		t.Fatalf("Expected 3 nodes but got %d: %v", count, err)
+	}
+
+	quantities := map[string]struct {
+		fn           func(string, interface{}) (string, error)
+		resourceName string
+		expected     string
+	}{
+		"capacity_cpu":       {nodeCapacity, "cpu", "12500m"},
+		"capacity_memory":    {nodeCapacity, "memory", "56Gi"},
+		"allocatable_memory": {nodeAllocatable, "memory", "3Gi"},
+		"missing_resource":   {nodeAllocatable, "nvidia.com/gpu", "0"},
+	}
+
+	for testName, test := range quantities {
+		value, err := test.fn(test.resourceName, nodes)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", testName, err)
+		}
+
+		if value != test.expected {
+			t.Fatalf("%s: expected %s but got %s", testName, test.expected, value)
+		}
+	}
+
+	zones, err := nodeZones(nodes)
+	if err != nil || !reflect.DeepEqual(zones, []string{"us-east-1a", "us-east-1b"}) {
+		t.Fatalf("Unexpected zones %v: %v", zones, err)
+	}
+
+	regions, err := nodeRegions(nodes)
+	if err != nil || !reflect.DeepEqual(regions, []string{"us-east-1"}) {
+		t.Fatalf("Unexpected regions %v: %v", regions, err)
+	}
+
+	// The label takes precedence over the node info
+	architectures, err := nodeArchitectures(nodes)
+	if err != nil || !reflect.DeepEqual(architectures, map[string]interface{}{"amd64": 2, "arm64": 1}) {
+		t.Fatalf("Unexpected architectures %v: %v", architectures, err)
+	}
+
+	operatingSystems, err := nodeOperatingSystems(nodes)
+	if err != nil || !reflect.DeepEqual(operatingSystems, map[string]interface{}{"linux": 3}) {
+		t.Fatalf("Unexpected operating systems %v: %v", operatingSystems, err)
+	}
+
+	invalidNode := map[string]interface{}{
+		"kind":   "Node",
+		"status": map[string]interface{}{"capacity": map[string]interface{}{"cpu": "lots"}},
+	}
+	if _, err := nodeCapacity("cpu", []interface{}{invalidNode}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error for an invalid quantity but got: %v", err)
+	}
+
+	if _, err := nodeCount("not a list"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error for an invalid list but got: %v", err)
+	}
+}
+
+func TestNodeFunctionsTemplate(t *testing.T) {
+	t.Parallel()
+
+	doResolveTest(t, resolveTestCase{
+		inputTmpl: `{{ $nodes := fromJson .Nodes }}` +
+			`infra: '{{ getNodes "node-role.kubernetes.io/infra" | nodeCount }}'
+storage: '{{ getNodes "node-role.kubernetes.io/infra" "node-role.kubernetes.io/storage" | nodeCount }}'
+untainted: '{{ getNodes "node-role.kubernetes.io/infra" | nodesWithoutTaint "dedicated" | nodeCount }}'
+schedulable: '{{ $nodes | nodesWithoutTaint "dedicated:NoSchedule" | nodeAllocatable "cpu" }}'
+zones: '{{ $nodes | nodeZones | join "," }}'
+arm: '{{ (nodeArchitectures $nodes).arm64 }}'`,
+		ctx:            struct{ Nodes string }{testNodesJSON},
+		resolveOptions: ResolveOptions{InputIsYAML: true},
+		expectedResult: "arm: \"1\"\ninfra: \"3\"\nschedulable: 4500m\nstorage: \"1\"\nuntainted: \"3\"\n" +
+			"zones: us-east-1a,us-east-1b",
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (t *TemplateResolver) lookupOwnerHelper(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+) func(interface{}) (map[string]interface{}, error) {
+	return func(obj interface{}) (map[string]interface{}, error) {
+		return t.lookupOwner(options, templateResult, obj)
+	}
+}
+
+// lookupOwner returns the owner of the input object, such as the result of the "lookup" template function, from its
+// metadata.ownerReferences. The controller owner is preferred, otherwise the first owner is used. An empty result is
+// returned if the object has no owner or the owner doesn't exist, even in strict mode, since an owner may be deleted
+// before the objects it owns. An owner with a different UID than the owner reference is treated as not existing since
+// the original owner was deleted and recreated.
+func (t *TemplateResolver) lookupOwner(
+	options *ResolveOptions, templateResult *TemplateResult, obj interface{},
+) (map[string]interface{}, error) {
+	typedObj, err := conditionObject(obj)
+	if err != nil || typedObj == nil {
+		return nil, err
+	}
+
+	ownerRefs := typedObj.GetOwnerReferences()
+	if len(ownerRefs) == 0 {
+		return nil, nil
+	}
+
+	ownerRef := metav1.GetControllerOfNoCopy(typedObj)
+	if ownerRef == nil {
+		ownerRef = &ownerRefs[0]
+	}
+
+	gv, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: the owner reference API version %s is invalid", ErrInvalidInput, ownerRef.APIVersion,
+		)
+	}
+
+	// Owners must be in the same namespace as the object or be cluster-scoped
+	namespace := typedObj.GetNamespace()
+
+	scopedGVRObj, err := t.gvkToGVR(gv.WithKind(ownerRef.Kind))
+	if err != nil {
+		lookupErr := newLookupError(ownerRef.APIVersion, ownerRef.Kind, namespace, ownerRef.Name, err)
+
+		if lookupErrorIgnored(
+			options, templateResult, ownerRef.APIVersion, ownerRef.Kind, namespace, ownerRef.Name, lookupErr,
+		) {
+			return nil, nil
+		}
+
+		return nil, lookupErr
+	}
+
+	if !scopedGVRObj.Namespaced {
+		namespace = ""
+	}
+
+	// A missing owner is not an error in strict mode
+	lookupOptions := *options
+	lookupOptions.StrictMode = false
+
+	owner, err := t.lookup(
+		&lookupOptions, templateResult, ownerRef.APIVersion, ownerRef.Kind, namespace, ownerRef.Name,
+	)
+	if err != nil || owner == nil {
+		return owner, err
+	}
+
+	if uid, _, _ := unstructured.NestedString(owner, "metadata", "uid"); types.UID(uid) != ownerRef.UID {
+		return nil, nil
+	}
+
+	return owner, nil
+}
+
+func (t *TemplateResolver) lookupChildrenHelper(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+) func(string, string, string, interface{}) (map[string]interface{}, error) {
+	return func(apiVersion string, kind string, namespace string, owner interface{}) (map[string]interface{}, error) {
+		return t.lookupChildren(options, templateResult, apiVersion, kind, namespace, owner)
+	}
+}
+
+// lookupChildren returns a list of the objects of the input kind in the input namespace that have an owner reference
+// to the input owner. The owner can be a UID or an object, such as the result of the "lookup" template function. An
+// empty list is returned if the owner is empty.
+func (t *TemplateResolver) lookupChildren(
+	options *ResolveOptions,
+	templateResult *TemplateResult,
+	apiVersion string,
+	kind string,
+	namespace string,
+	owner interface{},
+) (map[string]interface{}, error) {
+	var ownerUID string
+
+	switch typedOwner := owner.(type) {
+	case string:
+		ownerUID = typedOwner
+	default:
+		ownerObj, err := conditionObject(owner)
+		if err != nil {
+			return nil, fmt.Errorf("%w: expected an owner UID or object but got %T", ErrInvalidInput, owner)
+		}
+
+		if ownerObj != nil {
+			ownerUID = string(ownerObj.GetUID())
+		}
+	}
+
+	if ownerUID == "" {
+		return map[string]interface{}{"items": []interface{}{}}, nil
+	}
+
+	result, err := t.lookup(options, templateResult, apiVersion, kind, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resultList := unstructured.UnstructuredList{}
+	resultList.SetUnstructuredContent(result)
+
+	children := unstructured.UnstructuredList{Items: []unstructured.Unstructured{}}
+
+	for _, item := range resultList.Items {
+		for _, ownerRef := range item.GetOwnerReferences() {
+			if string(ownerRef.UID) == ownerUID {
+				children.Items = append(children.Items, item)
+
+				break
+			}
+		}
+	}
+
+	return children.UnstructuredContent(), nil
+}
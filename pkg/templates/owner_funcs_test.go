@@ -0,0 +1,331 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ownerTestObjects are passed to newFakeLookupResolver. A Deployment owns two ReplicaSets, which own Pods, and a
+// ConfigMap is owned by a cluster-scoped Node. The UID of each object is its name with a "-uid" suffix.
+var ownerTestObjects = []runtime.Object{
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "app", "uid": "app-uid",
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "ReplicaSet",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "app-1", "uid": "app-1-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "Deployment", "name": "app", "uid": "app-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "ReplicaSet",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "app-2", "uid": "app-2-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "Deployment", "name": "app", "uid": "app-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "ReplicaSet",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "other", "uid": "other-uid",
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "app-1-a", "uid": "app-1-a-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "app-1", "uid": "app-1-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "app-1-b", "uid": "app-1-b-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "app-1", "uid": "app-1-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "app-2-a", "uid": "app-2-a-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "app-2", "uid": "app-2-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "stale", "uid": "stale-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "app-1", "uid": "deleted-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "orphan", "uid": "orphan-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "deleted", "uid": "deleted-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "multiple-owners", "uid": "multiple-owners-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "app-2", "uid": "app-2-uid",
+					"controller": false,
+				},
+				map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "Deployment", "name": "app", "uid": "app-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Node",
+		"metadata": map[string]interface{}{
+			"name": "node1", "uid": "node1-uid",
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "node-config", "uid": "node-config-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "v1", "kind": "Node", "name": "node1", "uid": "node1-uid",
+					"controller": false,
+				},
+			},
+		},
+	}},
+	&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"namespace": "default", "name": "missing-api", "uid": "missing-api-uid",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "example.com/v1", "kind": "Widget", "name": "widget", "uid": "widget-uid",
+					"controller": true,
+				},
+			},
+		},
+	}},
+}
+
+func TestLookupOwner(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t, ownerTestObjects...)
+
+	testcases := map[string]struct {
+		kind          string
+		name          string
+		options       ResolveOptions
+		expectedOwner string
+		expectedErr   error
+	}{
+		"pod_owner":                 {"Pod", "app-1-a", ResolveOptions{}, "app-1", nil},
+		"replicaset_owner":          {"ReplicaSet", "app-2", ResolveOptions{}, "app", nil},
+		"controller_first":          {"Pod", "multiple-owners", ResolveOptions{}, "app", nil},
+		"no_owner":                  {"Deployment", "app", ResolveOptions{}, "", nil},
+		"stale_owner":               {"Pod", "stale", ResolveOptions{}, "", nil},
+		"missing_owner":             {"Pod", "orphan", ResolveOptions{}, "", nil},
+		"missing_owner_strict_mode": {"Pod", "orphan", ResolveOptions{StrictMode: true}, "", nil},
+		"stale_owner_strict_mode":   {"Pod", "stale", ResolveOptions{StrictMode: true}, "", nil},
+		"cluster_scoped":            {"ConfigMap", "node-config", ResolveOptions{}, "node1", nil},
+		"missing_api":               {"ConfigMap", "missing-api", ResolveOptions{}, "", ErrMissingAPIResource},
+		"missing_api_ignore": {
+			"ConfigMap", "missing-api", ResolveOptions{LookupIgnoreMissingAPIResource: true}, "", nil,
+		},
+		"cluster_scoped_restricted": {
+			"ConfigMap",
+			"node-config",
+			ResolveOptions{LookupNamespace: "default"},
+			"",
+			ErrClusterScopedLookupRestricted,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			apiVersion := "v1"
+			if test.kind == "Deployment" || test.kind == "ReplicaSet" {
+				apiVersion = "apps/v1"
+			}
+
+			obj, err := resolver.lookup(&ResolveOptions{}, nil, apiVersion, test.kind, "default", test.name)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			owner, err := resolver.lookupOwner(&test.options, &TemplateResult{}, obj)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			ownerName, _, _ := unstructured.NestedString(owner, "metadata", "name")
+			if ownerName != test.expectedOwner {
+				t.Fatalf("Expected the owner %q but got %q", test.expectedOwner, ownerName)
+			}
+		})
+	}
+
+	owner, err := resolver.lookupOwner(&ResolveOptions{}, nil, nil)
+	if err != nil || owner != nil {
+		t.Fatalf("Expected an empty result for an object that doesn't exist but got %v: %v", owner, err)
+	}
+}
+
+func TestLookupChildren(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t, ownerTestObjects...)
+
+	deployment, err := resolver.lookup(&ResolveOptions{}, nil, "apps/v1", "Deployment", "default", "app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testcases := map[string]struct {
+		apiVersion    string
+		kind          string
+		owner         interface{}
+		expectedNames string
+	}{
+		"owner_object":   {"apps/v1", "ReplicaSet", deployment, "app-1,app-2"},
+		"owner_uid":      {"v1", "Pod", "app-1-uid", "app-1-a,app-1-b"},
+		"any_owner":      {"v1", "Pod", "app-2-uid", "app-2-a,multiple-owners"},
+		"no_children":    {"v1", "Pod", "other-uid", ""},
+		"empty_owner":    {"v1", "Pod", map[string]interface{}{}, ""},
+		"nil_owner":      {"v1", "Pod", nil, ""},
+		"empty_uid":      {"v1", "Pod", "", ""},
+		"not_found_kind": {"v1", "Secret", "app-1-uid", ""},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			children, err := resolver.lookupChildren(
+				&ResolveOptions{LookupIgnoreMissingAPIResource: true},
+				nil,
+				test.apiVersion,
+				test.kind,
+				"default",
+				test.owner,
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			names := []string{}
+
+			childList := unstructured.UnstructuredList{}
+			childList.SetUnstructuredContent(children)
+
+			for _, child := range childList.Items {
+				names = append(names, child.GetName())
+			}
+
+			slices.Sort(names)
+
+			joinedNames := strings.Join(names, ",")
+
+			if joinedNames != test.expectedNames {
+				t.Fatalf("Expected the children %q but got %q", test.expectedNames, joinedNames)
+			}
+		})
+	}
+
+	_, err = resolver.lookupChildren(&ResolveOptions{}, nil, "v1", "Pod", "default", 5)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error but got: %v", err)
+	}
+}
+
+func TestOwnerFunctionsTemplate(t *testing.T) {
+	t.Parallel()
+
+	resolver := newFakeLookupResolver(t, ownerTestObjects...)
+
+	tmpl := `{{ $deployment := lookup "v1" "Pod" "default" "app-1-a" | lookupOwner | lookupOwner }}` +
+		`deployment: '{{ $deployment.metadata.name }}'
+pods: '{{ range (lookupChildren "apps/v1" "ReplicaSet" "default" $deployment).items }}` +
+		`{{ len (lookupChildren "v1" "Pod" "default" .).items }}{{ end }}'`
+
+	result, err := resolver.ResolveTemplate([]byte(tmpl), nil, &ResolveOptions{InputIsYAML: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"deployment":"app","pods":"22"}`
+	if string(result.ResolvedJSON) != expected {
+		t.Fatalf("Expected %s but got: %s", expected, result.ResolvedJSON)
+	}
+}
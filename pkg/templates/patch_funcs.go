@@ -0,0 +1,143 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	yaml "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// patchObjectJSON returns the input object, such as the result of the "lookup" template function, as JSON.
+func patchObjectJSON(obj interface{}) ([]byte, error) {
+	typedObj, ok := obj.(map[string]interface{})
+	if !ok || len(typedObj) == 0 {
+		return nil, fmt.Errorf("%w: expected an object to patch but got %T", ErrInvalidInput, obj)
+	}
+
+	objJSON, err := json.Marshal(typedObj)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the object to patch can't be converted to JSON: %w", ErrInvalidInput, err)
+	}
+
+	return objJSON, nil
+}
+
+// patchJSON returns the input patch as JSON. The patch can be a JSON or YAML string, or a map or list built in the
+// template (e.g. with "dict" and "list").
+func patchJSON(patch interface{}) ([]byte, error) {
+	if patchStr, ok := patch.(string); ok {
+		var parsedPatch interface{}
+
+		if err := yaml.Unmarshal([]byte(patchStr), &parsedPatch); err != nil {
+			return nil, fmt.Errorf("%w: the patch is not valid JSON or YAML: %w", ErrInvalidInput, err)
+		}
+
+		patch = parsedPatch
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the patch can't be converted to JSON: %w", ErrInvalidInput, err)
+	}
+
+	return patchBytes, nil
+}
+
+// patchedObject converts the patched JSON back to a map. Whole numbers are converted to int64 to match the result of
+// the "lookup" template function.
+func patchedObject(patchedJSON []byte) (map[string]interface{}, error) {
+	patched := map[string]interface{}{}
+
+	if err := utiljson.Unmarshal(patchedJSON, &patched); err != nil {
+		return nil, fmt.Errorf("%w: the patched object is not a JSON object: %w", ErrInvalidInput, err)
+	}
+
+	return patched, nil
+}
+
+// jsonPatch applies the input RFC 6902 JSON patch (e.g. `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`)
+// to the input object and returns the patched copy. The input object is not modified.
+func jsonPatch(patch interface{}, obj interface{}) (map[string]interface{}, error) {
+	objJSON, err := patchObjectJSON(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	patchBytes, err := patchJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	decodedPatch, err := jsonpatch.DecodePatch(patchBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the JSON patch is invalid: %w", ErrInvalidInput, err)
+	}
+
+	patchedJSON, err := decodedPatch.Apply(objJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply the JSON patch: %w", err)
+	}
+
+	return patchedObject(patchedJSON)
+}
+
+// mergePatch applies the input RFC 7386 JSON merge patch (e.g. `{"spec": {"replicas": 3}}`) to the input object and
+// returns the patched copy. Maps are merged, while other values including lists are replaced, and null values remove
+// fields. The input object is not modified.
+func mergePatch(patch interface{}, obj interface{}) (map[string]interface{}, error) {
+	objJSON, err := patchObjectJSON(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	patchBytes, err := patchJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	patchedJSON, err := jsonpatch.MergePatch(objJSON, patchBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to apply the merge patch: %w", ErrInvalidInput, err)
+	}
+
+	return patchedObject(patchedJSON)
+}
+
+// strategicMergePatch applies the input strategic merge patch to the input object and returns the patched copy. This
+// behaves like "kubectl patch --type strategic", so lists such as the containers of a Deployment are merged by their
+// key (e.g. name) rather than replaced. It's only supported for the built-in Kubernetes types since the merge keys
+// come from the Go types. The input object is not modified.
+func strategicMergePatch(patch interface{}, obj interface{}) (map[string]interface{}, error) {
+	objJSON, err := patchObjectJSON(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := (&unstructured.Unstructured{Object: obj.(map[string]interface{})}).GroupVersionKind()
+
+	dataStruct, err := scheme.Scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: a strategic merge patch is not supported for %s, use mergePatch instead", ErrInvalidInput, gvk,
+		)
+	}
+
+	patchBytes, err := patchJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	patchedJSON, err := strategicpatch.StrategicMergePatch(objJSON, patchBytes, dataStruct)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to apply the strategic merge patch: %w", ErrInvalidInput, err)
+	}
+
+	return patchedObject(patchedJSON)
+}
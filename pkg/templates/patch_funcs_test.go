@@ -0,0 +1,172 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPatchFunctions(t *testing.T) {
+	t.Parallel()
+
+	deployment := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "app",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "app", "tier": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:v1"},
+						map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+					},
+				},
+			},
+		},
+	}
+
+	testcases := map[string]struct {
+		fn          func(interface{}, interface{}) (map[string]interface{}, error)
+		patch       interface{}
+		obj         interface{}
+		field       string
+		expected    interface{}
+		expectedErr string
+	}{
+		"json_patch_replace": {
+			fn:       jsonPatch,
+			patch:    `[{"op": "replace", "path": "/spec/replicas", "value": 3}]`,
+			field:    "spec.replicas",
+			expected: int64(3),
+		},
+		"json_patch_yaml": {
+			fn:       jsonPatch,
+			patch:    "- op: remove\n  path: /metadata/labels/tier",
+			field:    "metadata.labels",
+			expected: map[string]interface{}{"app": "app"},
+		},
+		"json_patch_list": {
+			fn: jsonPatch,
+			patch: []interface{}{
+				map[string]interface{}{"op": "add", "path": "/metadata/labels/env", "value": "prod"},
+			},
+			field:    "metadata.labels.env",
+			expected: "prod",
+		},
+		"json_patch_test_failed": {
+			fn:          jsonPatch,
+			patch:       `[{"op": "test", "path": "/spec/replicas", "value": 2}]`,
+			expectedErr: "failed to apply the JSON patch: testing value /spec/replicas failed",
+		},
+		"json_patch_invalid": {
+			fn:          jsonPatch,
+			patch:       `{"op": "replace"}`,
+			expectedErr: "the input is invalid: the JSON patch is invalid",
+		},
+		"merge_patch": {
+			fn:       mergePatch,
+			patch:    map[string]interface{}{"spec": map[string]interface{}{"replicas": 3}},
+			field:    "spec.replicas",
+			expected: int64(3),
+		},
+		"merge_patch_remove": {
+			fn:       mergePatch,
+			patch:    `{"metadata": {"labels": {"tier": null}}}`,
+			field:    "metadata.labels",
+			expected: map[string]interface{}{"app": "app"},
+		},
+		"merge_patch_replaces_lists": {
+			fn:       mergePatch,
+			patch:    `{"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "app:v2"}]}}}}`,
+			field:    "spec.template.spec.containers",
+			expected: []interface{}{map[string]interface{}{"name": "app", "image": "app:v2"}},
+		},
+		"strategic_merge_patch_merges_lists": {
+			fn:    strategicMergePatch,
+			patch: `{"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "app:v2"}]}}}}`,
+			field: "spec.template.spec.containers",
+			expected: []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:v2"},
+				map[string]interface{}{"name": "sidecar", "image": "sidecar:v1"},
+			},
+		},
+		"strategic_merge_patch_unknown_type": {
+			fn:          strategicMergePatch,
+			patch:       `{"spec": {"replicas": 3}}`,
+			obj:         map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget"},
+			expectedErr: "a strategic merge patch is not supported for example.com/v1, Kind=Widget",
+		},
+		"invalid_object": {
+			fn:          mergePatch,
+			patch:       `{"spec": {"replicas": 3}}`,
+			obj:         map[string]interface{}{},
+			expectedErr: "expected an object to patch",
+		},
+		"invalid_patch": {
+			fn:          mergePatch,
+			patch:       `{"spec": `,
+			expectedErr: "the patch is not valid JSON or YAML",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			obj := test.obj
+			if obj == nil {
+				obj = runtime.DeepCopyJSON(deployment)
+			}
+
+			original := runtime.DeepCopyJSONValue(obj)
+
+			patched, err := test.fn(test.patch, obj)
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("Expected the error %q but got: %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			value, _, _ := itemField(patched, test.field)
+			if !reflect.DeepEqual(value, test.expected) {
+				t.Fatalf("Expected %v (%T) but got %v (%T)", test.expected, test.expected, value, value)
+			}
+
+			// The input object must not be modified
+			if !reflect.DeepEqual(obj, original) {
+				t.Fatalf("The input object was modified: %v", obj)
+			}
+		})
+	}
+}
+
+func TestPatchFunctionsTemplate(t *testing.T) {
+	t.Parallel()
+
+	doResolveTest(t, resolveTestCase{
+		inputTmpl: `{{ $cm := lookup "v1" "ConfigMap" "testns" "testconfigmap" | ` +
+			`mergePatch (dict "data" (dict "cmkey1" "patched" "cmkey2" nil)) }}` +
+			`cmkey1: '{{ $cm.data.cmkey1 }}'
+hasCmkey2: '{{ hasKey $cm.data "cmkey2" }}'
+name: '{{ $cm.metadata.name }}'`,
+		resolveOptions: ResolveOptions{InputIsYAML: true},
+		expectedResult: "cmkey1: patched\nhasCmkey2: \"false\"\nname: testconfigmap",
+	})
+}
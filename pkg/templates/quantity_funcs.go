@@ -0,0 +1,153 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/inf.v0"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// quantityDecimalScale is the number of decimal places kept when a quantity is converted to a unit.
+const quantityDecimalScale = 3
+
+// toQuantity parses the input as a Kubernetes resource quantity (e.g. "500Mi", "250m", or 2).
+func toQuantity(value interface{}) (resource.Quantity, error) {
+	var str string
+
+	switch typedValue := value.(type) {
+	case resource.Quantity:
+		return typedValue, nil
+	case *resource.Quantity:
+		if typedValue == nil {
+			return resource.Quantity{}, fmt.Errorf("%w: the quantity is nil", ErrInvalidInput)
+		}
+
+		return *typedValue, nil
+	case string:
+		str = typedValue
+	case int, int32, int64, float32, float64:
+		str = fmt.Sprint(typedValue)
+	default:
+		return resource.Quantity{}, fmt.Errorf("%w: expected a quantity but got %T", ErrInvalidInput, value)
+	}
+
+	quantity, err := resource.ParseQuantity(strings.TrimSpace(str))
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("%w: the quantity %s is invalid: %w", ErrInvalidInput, str, err)
+	}
+
+	return quantity, nil
+}
+
+// addQuantity returns the sum of the input quantities (e.g. "1Gi" + "512Mi" = "1536Mi").
+func addQuantity(a interface{}, b interface{}) (string, error) {
+	quantityA, err := toQuantity(a)
+	if err != nil {
+		return "", err
+	}
+
+	quantityB, err := toQuantity(b)
+	if err != nil {
+		return "", err
+	}
+
+	quantityA.Add(quantityB)
+
+	return quantityA.String(), nil
+}
+
+// subQuantity returns the first quantity minus the second quantity.
+func subQuantity(a interface{}, b interface{}) (string, error) {
+	quantityA, err := toQuantity(a)
+	if err != nil {
+		return "", err
+	}
+
+	quantityB, err := toQuantity(b)
+	if err != nil {
+		return "", err
+	}
+
+	quantityA.Sub(quantityB)
+
+	return quantityA.String(), nil
+}
+
+// mulQuantity returns the input quantity multiplied by the input factor (e.g. 0.8). The factor is the first argument
+// so that the quantity can be piped to the function. Like the Kubernetes API server, the result is rounded up to the
+// nearest milli unit, or to the nearest whole unit for binary quantities (e.g. "4Gi") since they represent bytes.
+func mulQuantity(factor interface{}, value interface{}) (string, error) {
+	parsedFactor, err := toQuantity(factor)
+	if err != nil {
+		return "", err
+	}
+
+	quantity, err := toQuantity(value)
+	if err != nil {
+		return "", err
+	}
+
+	scale := inf.Scale(-resource.Milli)
+	if quantity.Format == resource.BinarySI {
+		scale = 0
+	}
+
+	product := new(inf.Dec).Mul(quantity.AsDec(), parsedFactor.AsDec())
+	product.Round(product, scale, inf.RoundCeil)
+
+	return resource.NewDecimalQuantity(*product, quantity.Format).String(), nil
+}
+
+// compareQuantity returns -1 if the first quantity is less than the second quantity, 0 if they are equal, and 1 if the
+// first quantity is greater.
+func compareQuantity(a interface{}, b interface{}) (int, error) {
+	quantityA, err := toQuantity(a)
+	if err != nil {
+		return 0, err
+	}
+
+	quantityB, err := toQuantity(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return quantityA.Cmp(quantityB), nil
+}
+
+// formatQuantity formats the input quantity. The format can be BinarySI (e.g. "1536Mi"), DecimalSI (e.g. "1.5G"), or
+// DecimalExponent (e.g. "1.5e9") to use the canonical form of that format, or a unit suffix (e.g. "Mi", "G", "m", or
+// "" for no unit) to express the quantity in that unit, rounded to three decimal places (e.g. "1.5Gi" with "Mi" is
+// "1536Mi").
+func formatQuantity(format string, value interface{}) (string, error) {
+	quantity, err := toQuantity(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch resource.Format(format) {
+	case resource.BinarySI, resource.DecimalSI, resource.DecimalExponent:
+		return resource.NewDecimalQuantity(*quantity.AsDec(), resource.Format(format)).String(), nil
+	}
+
+	unit, err := resource.ParseQuantity("1" + format)
+	if err != nil {
+		return "", fmt.Errorf(
+			"%w: the format %s must be BinarySI, DecimalSI, DecimalExponent, or a quantity suffix",
+			ErrInvalidInput,
+			format,
+		)
+	}
+
+	converted := new(inf.Dec).QuoRound(quantity.AsDec(), unit.AsDec(), quantityDecimalScale, inf.RoundHalfUp)
+
+	// Remove trailing zeros in the decimal places (e.g. 1.500 to 1.5)
+	convertedStr := converted.String()
+	if strings.Contains(convertedStr, ".") {
+		convertedStr = strings.TrimRight(strings.TrimRight(convertedStr, "0"), ".")
+	}
+
+	return convertedStr + format, nil
+}
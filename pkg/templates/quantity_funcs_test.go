@@ -0,0 +1,145 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestQuantityFunctions(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		fn          func(interface{}, interface{}) (string, error)
+		a           interface{}
+		b           interface{}
+		expected    string
+		expectedErr error
+	}{
+		"add_binary":       {addQuantity, "1Gi", "512Mi", "1536Mi", nil},
+		"add_cpu":          {addQuantity, "1", "250m", "1250m", nil},
+		"add_int":          {addQuantity, 2, "500m", "2500m", nil},
+		"add_quantity":     {addQuantity, resource.MustParse("1Gi"), "1Gi", "2Gi", nil},
+		"sub_binary":       {subQuantity, "2Gi", "512Mi", "1536Mi", nil},
+		"sub_negative":     {subQuantity, "250m", "1", "-750m", nil},
+		"mul_factor":       {mulQuantity, 0.8, "4Gi", "3435973837", nil},
+		"mul_string":       {mulQuantity, "0.5", "4Gi", "2Gi", nil},
+		"mul_cpu":          {mulQuantity, 3, "250m", "750m", nil},
+		"mul_rounded_up":   {mulQuantity, 0.5, "1m", "1m", nil},
+		"mul_decimal":      {mulQuantity, 0.5, "1", "500m", nil},
+		"add_invalid":      {addQuantity, "1Gi", "lots", "", ErrInvalidInput},
+		"sub_invalid_type": {subQuantity, "1Gi", []interface{}{}, "", ErrInvalidInput},
+		"mul_invalid":      {mulQuantity, "half", "1Gi", "", ErrInvalidInput},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := test.fn(test.a, test.b)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			if value != test.expected {
+				t.Fatalf("Expected %q but got %q", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestCompareQuantity(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		a        interface{}
+		b        interface{}
+		expected int
+	}{
+		"less":          {"500Mi", "1Gi", -1},
+		"equal":         {"1024Mi", "1Gi", 0},
+		"equal_cpu":     {"1", "1000m", 0},
+		"greater":       {"1G", "900Mi", 1},
+		"greater_float": {1.5, "1", 1},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := compareQuantity(test.a, test.b)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if value != test.expected {
+				t.Fatalf("Expected %d but got %d", test.expected, value)
+			}
+		})
+	}
+
+	if _, err := compareQuantity("1Gi", ""); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected an invalid input error but got: %v", err)
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		format      string
+		value       interface{}
+		expected    string
+		expectedErr error
+	}{
+		"binary_si":        {"BinarySI", "1536Mi", "1536Mi", nil},
+		"decimal_si":       {"DecimalSI", "1536Mi", "1610612736", nil},
+		"decimal_si_cpu":   {"DecimalSI", "1.5", "1500m", nil},
+		"decimal_exponent": {"DecimalExponent", "1.5G", "1500e6", nil},
+		"unit_binary":      {"Mi", "1.5Gi", "1536Mi", nil},
+		"unit_fraction":    {"Gi", "1536Mi", "1.5Gi", nil},
+		"unit_rounded":     {"Gi", "1G", "0.931Gi", nil},
+		"unit_milli":       {"m", "1.5", "1500m", nil},
+		"unit_none":        {"", "250m", "0.25", nil},
+		"unit_integer":     {"", "2k", "2000", nil},
+		"invalid_format":   {"Megabytes", "1Gi", "", ErrInvalidInput},
+		"invalid_quantity": {"Mi", "1 GB", "", ErrInvalidInput},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := formatQuantity(test.format, test.value)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+
+			if value != test.expected {
+				t.Fatalf("Expected %q but got %q", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestQuantityFunctionsTemplate(t *testing.T) {
+	t.Parallel()
+
+	doResolveTest(t, resolveTestCase{
+		inputTmpl: `total: '{{ addQuantity .Requests "512Mi" }}'
+reserved: '{{ .Limit | mulQuantity 0.5 | formatQuantity "Mi" }}'
+fits: '{{ lt (compareQuantity .Requests .Limit) 0 }}'`,
+		ctx:            struct{ Requests, Limit string }{"1Gi", "4Gi"},
+		resolveOptions: ResolveOptions{InputIsYAML: true},
+		expectedResult: "fits: \"true\"\nreserved: 2048Mi\ntotal: 1536Mi",
+	})
+}
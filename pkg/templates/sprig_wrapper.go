@@ -4,9 +4,40 @@
 package templates
 
 import (
+	"fmt"
+	"slices"
+	"sort"
+
 	sprig "github.com/Masterminds/sprig/v3"
 )
 
+// SprigCategory is a category of Sprig functions as documented at https://masterminds.github.io/sprig/. It is used in
+// Config.AdditionalSprigCategories to enable all the Sprig functions in the category.
+type SprigCategory string
+
+const (
+	SprigCategoryCrypto         SprigCategory = "crypto"
+	SprigCategoryDate           SprigCategory = "date"
+	SprigCategoryDefaults       SprigCategory = "defaults"
+	SprigCategoryDictionaries   SprigCategory = "dictionaries"
+	SprigCategoryEncoding       SprigCategory = "encoding"
+	SprigCategoryFloatMath      SprigCategory = "floatMath"
+	SprigCategoryFlowControl    SprigCategory = "flowControl"
+	SprigCategoryIntegerMath    SprigCategory = "integerMath"
+	SprigCategoryLists          SprigCategory = "lists"
+	SprigCategoryNetwork        SprigCategory = "network"
+	SprigCategoryOS             SprigCategory = "os"
+	SprigCategoryPaths          SprigCategory = "paths"
+	SprigCategoryReflection     SprigCategory = "reflection"
+	SprigCategoryRegex          SprigCategory = "regex"
+	SprigCategorySemver         SprigCategory = "semver"
+	SprigCategoryStringSlices   SprigCategory = "stringSlices"
+	SprigCategoryStrings        SprigCategory = "strings"
+	SprigCategoryTypeConversion SprigCategory = "typeConversion"
+	SprigCategoryURL            SprigCategory = "url"
+	SprigCategoryUUID           SprigCategory = "uuid"
+)
+
 var (
 	sprigFuncMap = sprig.FuncMap()
 
@@ -74,6 +105,76 @@ var (
 		"untilStep",
 		"upper",
 	}
+
+	// sprigCategories maps each Sprig category to its functions. Functions provided by this library with the same name
+	// (e.g. "indent") are skipped when enabling a category.
+	sprigCategories = map[SprigCategory][]string{
+		SprigCategoryCrypto: {
+			"adler32sum", "bcrypt", "buildCustomCert", "decryptAES", "derivePassword", "encryptAES", "genCA",
+			"genCAWithKey", "genPrivateKey", "genSelfSignedCert", "genSelfSignedCertWithKey", "genSignedCert",
+			"genSignedCertWithKey", "htpasswd", "randBytes", "sha1sum", "sha256sum",
+		},
+		SprigCategoryDate: {
+			"ago", "date", "dateInZone", "dateModify", "duration", "durationRound", "htmlDate", "htmlDateInZone",
+			"mustDateModify", "mustToDate", "now", "toDate", "unixEpoch",
+		},
+		SprigCategoryDefaults: {
+			"all", "any", "coalesce", "compact", "deepCopy", "default", "empty", "fromJson", "mustCompact",
+			"mustDeepCopy", "mustFromJson", "mustToJson", "mustToPrettyJson", "mustToRawJson", "ternary", "toJson",
+			"toPrettyJson", "toRawJson",
+		},
+		SprigCategoryDictionaries: {
+			"dict", "dig", "get", "hasKey", "keys", "merge", "mergeOverwrite", "mustMerge", "mustMergeOverwrite",
+			"omit", "pick", "pluck", "set", "unset", "values",
+		},
+		SprigCategoryEncoding:    {"b32dec", "b32enc", "b64dec", "b64enc"},
+		SprigCategoryFloatMath:   {"add1f", "addf", "divf", "maxf", "minf", "mulf", "subf"},
+		SprigCategoryFlowControl: {"fail"},
+		SprigCategoryIntegerMath: {
+			"add", "add1", "biggest", "ceil", "div", "floor", "max", "min", "mod", "mul", "randInt", "round", "seq",
+			"sub", "until", "untilStep",
+		},
+		SprigCategoryLists: {
+			"append", "chunk", "compact", "concat", "first", "has", "initial", "last", "list", "mustAppend",
+			"mustChunk", "mustCompact", "mustFirst", "mustHas", "mustInitial", "mustLast", "mustPrepend", "mustPush",
+			"mustRest", "mustReverse", "mustSlice", "mustUniq", "mustWithout", "prepend", "push", "rest", "reverse",
+			"slice", "tuple", "uniq", "without",
+		},
+		SprigCategoryNetwork: {"getHostByName"},
+		SprigCategoryOS:      {"env", "expandenv"},
+		SprigCategoryPaths: {
+			"base", "clean", "dir", "ext", "isAbs", "osBase", "osClean", "osDir", "osExt", "osIsAbs",
+		},
+		SprigCategoryReflection: {"deepEqual", "kindIs", "kindOf", "typeIs", "typeIsLike", "typeOf"},
+		SprigCategoryRegex: {
+			"mustRegexFind", "mustRegexFindAll", "mustRegexMatch", "mustRegexReplaceAll",
+			"mustRegexReplaceAllLiteral", "mustRegexSplit", "regexFind", "regexFindAll", "regexMatch",
+			"regexQuoteMeta", "regexReplaceAll", "regexReplaceAllLiteral", "regexSplit",
+		},
+		SprigCategorySemver:       {"semver", "semverCompare"},
+		SprigCategoryStringSlices: {"join", "sortAlpha", "split", "splitList", "splitn", "toStrings"},
+		SprigCategoryStrings: {
+			"abbrev", "abbrevboth", "camelcase", "cat", "contains", "hasPrefix", "hasSuffix", "indent", "initials",
+			"kebabcase", "lower", "nindent", "nospace", "plural", "quote", "randAlpha", "randAlphaNum", "randAscii",
+			"randNumeric", "repeat", "replace", "shuffle", "snakecase", "squote", "substr", "swapcase", "title",
+			"trim", "trimAll", "trimPrefix", "trimSuffix", "trunc", "untitle", "upper", "wrap", "wrapWith",
+		},
+		SprigCategoryTypeConversion: {"atoi", "float64", "int", "int64", "toDecimal", "toString", "toStrings"},
+		SprigCategoryURL:            {"urlJoin", "urlParse"},
+		SprigCategoryUUID:           {"uuidv4"},
+	}
+
+	// nonDeterministicSprigFunctions lists the Sprig functions whose output is random or depends on the environment of
+	// the process. These cause the resolved template to change on every resolution, which leads to policies
+	// flapping, so they can only be enabled with Config.AllowNonDeterministicSprigFunctions. Note that htpasswd uses a
+	// random salt but remains in exportedSprigFunctions for backwards compatibility. Time-based functions such as ago
+	// aren't listed since they use the clock configured on the resolver.
+	nonDeterministicSprigFunctions = []string{
+		"bcrypt", "encryptAES", "env", "expandenv", "genCA", "genCAWithKey", "genPrivateKey",
+		"genSelfSignedCert", "genSelfSignedCertWithKey", "genSignedCert", "genSignedCertWithKey", "getHostByName",
+		"htpasswd", "randAlpha", "randAlphaNum", "randAscii", "randBytes", "randInt", "randNumeric", "shuffle",
+		"uuidv4",
+	}
 )
 
 func getSprigFunc(funcName string) (result interface{}) {
@@ -85,3 +186,88 @@ func getSprigFunc(funcName string) (result interface{}) {
 func AvailableSprigFunctions() []string {
 	return append(make([]string, 0, len(exportedSprigFunctions)), exportedSprigFunctions...)
 }
+
+// SprigFunctionsInCategory returns the names of the Sprig functions in the input category, sorted by name. This
+// includes functions that are also provided by this library and non-deterministic functions, which
+// Config.AdditionalSprigCategories skips unless AllowNonDeterministicSprigFunctions is set.
+func SprigFunctionsInCategory(category SprigCategory) []string {
+	functions := append([]string{}, sprigCategories[category]...)
+	sort.Strings(functions)
+
+	return functions
+}
+
+// validateSprigConfig validates that the additional Sprig functions and categories in the input Config exist and
+// that non-deterministic functions are only explicitly enabled when allowed.
+func validateSprigConfig(config Config) error {
+	for _, category := range config.AdditionalSprigCategories {
+		if _, ok := sprigCategories[category]; !ok {
+			return fmt.Errorf("%w: %s is not a Sprig category", ErrInvalidSprigFunction, category)
+		}
+	}
+
+	for _, fname := range config.AdditionalSprigFunctions {
+		if _, ok := sprigFuncMap[fname]; !ok {
+			return fmt.Errorf("%w: %s is not a Sprig function", ErrInvalidSprigFunction, fname)
+		}
+
+		if _, ok := builtinFunctionMetadata[fname]; ok {
+			return fmt.Errorf(
+				"%w: %s is provided by this library and can't be replaced by the Sprig function",
+				ErrInvalidSprigFunction,
+				fname,
+			)
+		}
+
+		if !config.AllowNonDeterministicSprigFunctions && slices.Contains(nonDeterministicSprigFunctions, fname) {
+			return fmt.Errorf(
+				"%w: %s is non-deterministic and requires AllowNonDeterministicSprigFunctions",
+				ErrInvalidSprigFunction,
+				fname,
+			)
+		}
+	}
+
+	return nil
+}
+
+// sprigFunctionNames returns the names of the Sprig functions to expose based on the default set and the additional
+// Sprig functions and categories in the Config. Functions provided by this library are always skipped so that they
+// aren't replaced by the Sprig function of the same name.
+func (t *TemplateResolver) sprigFunctionNames() []string {
+	if len(t.config.AdditionalSprigFunctions) == 0 && len(t.config.AdditionalSprigCategories) == 0 {
+		return exportedSprigFunctions
+	}
+
+	names := append([]string{}, exportedSprigFunctions...)
+
+	addName := func(fname string) {
+		if _, ok := sprigFuncMap[fname]; !ok {
+			return
+		}
+
+		if _, ok := builtinFunctionMetadata[fname]; ok {
+			return
+		}
+
+		if !t.config.AllowNonDeterministicSprigFunctions && slices.Contains(nonDeterministicSprigFunctions, fname) {
+			return
+		}
+
+		if !slices.Contains(names, fname) {
+			names = append(names, fname)
+		}
+	}
+
+	for _, category := range t.config.AdditionalSprigCategories {
+		for _, fname := range sprigCategories[category] {
+			addName(fname)
+		}
+	}
+
+	for _, fname := range t.config.AdditionalSprigFunctions {
+		addName(fname)
+	}
+
+	return names
+}
@@ -4,8 +4,10 @@
 package templates
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"testing"
 
 	yaml "gopkg.in/yaml.v3"
@@ -304,3 +306,133 @@ data:
 		})
 	}
 }
+
+func TestSprigCategories(t *testing.T) {
+	t.Parallel()
+
+	for category, functions := range sprigCategories {
+		for _, fname := range functions {
+			if _, ok := sprigFuncMap[fname]; !ok {
+				t.Fatalf("The %s function in the %s category is not a Sprig function", fname, category)
+			}
+		}
+	}
+
+	for _, fname := range nonDeterministicSprigFunctions {
+		if _, ok := sprigFuncMap[fname]; !ok {
+			t.Fatalf("The non-deterministic function %s is not a Sprig function", fname)
+		}
+	}
+
+	if !slices.Contains(SprigFunctionsInCategory(SprigCategoryDictionaries), "pluck") {
+		t.Fatal("Expected the dictionaries category to contain pluck")
+	}
+}
+
+func TestAdditionalSprigFunctions(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]resolveTestCase{
+		"by_name": {
+			inputTmpl:      `value: '{{ "hello" | sha256sum | trunc 8 }}'`,
+			config:         Config{AdditionalSprigFunctions: []string{"sha256sum"}},
+			expectedResult: "value: 2cf24dba",
+		},
+		"by_category": {
+			inputTmpl: `value: '{{ list "b" "a" "b" | uniq | sortAlpha | join "," }}'`,
+			config: Config{
+				AdditionalSprigCategories: []SprigCategory{SprigCategoryLists, SprigCategoryStringSlices},
+			},
+			expectedResult: "value: a,b",
+		},
+		"category_skips_builtin": {
+			inputTmpl:      `value: '{{ "VGVtcGxhdGVz" | b64dec }}{{ "a" | b32enc }}'`,
+			config:         Config{AdditionalSprigCategories: []SprigCategory{SprigCategoryEncoding}},
+			expectedResult: "value: TemplatesME======",
+		},
+		"category_skips_non_deterministic": {
+			inputTmpl: `value: '{{ randAlpha 5 }}'`,
+			config:    Config{AdditionalSprigCategories: []SprigCategory{SprigCategoryStrings}},
+			expectedErr: errors.New(
+				`failed to parse the template JSON string {"value":"{{ randAlpha 5 }}"}: template: tmpl:1: ` +
+					`function "randAlpha" not defined`,
+			),
+		},
+		"non_deterministic_allowed": {
+			inputTmpl: `value: '{{ randAlpha 5 | len }}'`,
+			config: Config{
+				AdditionalSprigFunctions:            []string{"randAlpha"},
+				AllowNonDeterministicSprigFunctions: true,
+			},
+			expectedResult: `value: "5"`,
+		},
+		"disabled": {
+			inputTmpl: `value: '{{ "hello" | sha256sum }}'`,
+			config: Config{
+				AdditionalSprigFunctions: []string{"sha256sum"},
+				DisabledFunctions:        []string{"sha256sum"},
+			},
+			expectedErr: ErrFunctionDisabled,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			doResolveTest(t, test)
+		})
+	}
+}
+
+func TestAdditionalSprigFunctionsInvalid(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		config      Config
+		expectedErr string
+	}{
+		"unknown_function": {
+			Config{AdditionalSprigFunctions: []string{"notAFunction"}},
+			"the Sprig function can't be enabled: notAFunction is not a Sprig function",
+		},
+		"unknown_category": {
+			Config{AdditionalSprigCategories: []SprigCategory{"notACategory"}},
+			"the Sprig function can't be enabled: notACategory is not a Sprig category",
+		},
+		"builtin": {
+			Config{AdditionalSprigFunctions: []string{"indent"}},
+			"the Sprig function can't be enabled: indent is provided by this library and can't be replaced by the " +
+				"Sprig function",
+		},
+		"non_deterministic": {
+			Config{AdditionalSprigFunctions: []string{"uuidv4"}},
+			"the Sprig function can't be enabled: uuidv4 is non-deterministic and requires " +
+				"AllowNonDeterministicSprigFunctions",
+		},
+		"non_deterministic_htpasswd": {
+			Config{AdditionalSprigFunctions: []string{"htpasswd"}},
+			"the Sprig function can't be enabled: htpasswd is non-deterministic and requires " +
+				"AllowNonDeterministicSprigFunctions",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewResolver(k8sConfig, test.config)
+			if !errors.Is(err, ErrInvalidSprigFunction) {
+				t.Fatalf("Expected ErrInvalidSprigFunction but got: %v", err)
+			}
+
+			if err.Error() != test.expectedErr {
+				t.Fatalf("Expected the error %q but got %q", test.expectedErr, err.Error())
+			}
+		})
+	}
+}
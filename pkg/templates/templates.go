@@ -12,14 +12,17 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/spf13/cast"
 	"github.com/stolostron/kubernetes-dependency-watches/client"
 	yaml "gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -27,6 +30,7 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -56,20 +60,91 @@ var (
 	ErrMissingNamespace = errors.New(
 		"the lookup of a single namespaced resource must have a namespace specified",
 	)
-	ErrRestrictedNamespace      = errors.New("the namespace argument is restricted")
-	ErrInvalidInput             = errors.New("the input is invalid")
-	ErrCacheDisabled            = client.ErrCacheDisabled
-	ErrNoCacheEntry             = client.ErrNoCacheEntry
-	ErrContextTransformerFailed = errors.New("the context transformer failed")
+	ErrRestrictedNamespace           = errors.New("the namespace argument is restricted")
+	ErrInvalidInput                  = errors.New("the input is invalid")
+	ErrCacheDisabled                 = client.ErrCacheDisabled
+	ErrNoCacheEntry                  = client.ErrNoCacheEntry
+	ErrContextTransformerFailed      = errors.New("the context transformer failed")
+	ErrInputConversionFailed         = errors.New("the template input conversion failed")
+	ErrDecryptionFailed              = errors.New("the template decryption failed")
+	ErrParseFailed                   = errors.New("the template parsing failed")
+	ErrExecutionFailed               = errors.New("the template execution failed")
+	ErrOutputConversionFailed        = errors.New("the resolved template conversion failed")
+	ErrLookupForbidden               = errors.New("the lookup is forbidden")
+	ErrLookupTimeout                 = errors.New("the lookup timed out")
+	ErrClusterScopedLookupRestricted = errors.New("the lookup of the cluster-scoped resource is not allowed")
+	ErrTplMaxDepthExceeded           = errors.New("the tpl function exceeded the maximum recursion depth")
+	ErrFunctionDisabled              = errors.New("the template function is disabled")
+	ErrInvalidSprigFunction          = errors.New("the Sprig function can't be enabled")
+	ErrObjectNotFound                = errors.New("the object was not found")
 )
 
+// ResolutionError is returned by ResolveTemplate when a stage of the template resolution fails. Stage is one of
+// ErrInputConversionFailed, ErrDecryptionFailed, ErrParseFailed, ErrContextTransformerFailed, ErrExecutionFailed, or
+// ErrOutputConversionFailed and Err is the underlying error. Both can be checked with errors.Is and errors.As. For
+// example, errors.Is(err, ErrParseFailed) is true for a template syntax error and errors.As(err, &lookupErr) with a
+// *LookupError is true when a lookup failed during the template execution.
+type ResolutionError struct {
+	Stage error
+	Err   error
+	msg   string
+}
+
+func (e *ResolutionError) Error() string {
+	if e.msg == "" {
+		return e.Err.Error()
+	}
+
+	return e.msg + ": " + e.Err.Error()
+}
+
+func (e *ResolutionError) Unwrap() []error {
+	return []error{e.Stage, e.Err}
+}
+
+// newResolutionError returns a ResolutionError for the input stage that wraps err. The optional format and args are
+// used as the message prefix.
+func newResolutionError(stage error, err error, format string, args ...interface{}) *ResolutionError {
+	msg := format
+	if len(args) != 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	return &ResolutionError{Stage: stage, Err: err, msg: msg}
+}
+
 // Config is a struct containing configuration for the API.
 //
 // - AdditionalIndentation sets the number of additional spaces to be added to the input number
 // to the indent method. This is useful in situations when the indentation should be relative
 // to a logical starting point in a YAML file.
 //
-// - DisabledFunctions is a slice of default template function names that should be disabled.
+// - AdditionalSprigCategories is a slice of Sprig categories (e.g. SprigCategoryLists) whose functions are exposed in
+// addition to the default Sprig functions. Non-deterministic functions in the category (e.g. "randAlpha") are skipped
+// unless AllowNonDeterministicSprigFunctions is set.
+//
+// - AdditionalSprigFunctions is a slice of Sprig function names (e.g. "sha256sum") to expose in addition to the default
+// Sprig functions. Functions provided by this library, such as "indent", can't be replaced.
+//
+// - AllowNonDeterministicSprigFunctions allows enabling Sprig functions whose output is random or depends on the
+// environment (e.g. "randAlpha", "uuidv4", "genPrivateKey", and "env"). These are blocked by default because a template
+// that resolves differently each time causes policies to flap.
+//
+// - AllowedFunctions is a slice of default template function names that are allowed. When set, any default template
+// function not in this list is disabled, so functions added in future versions of this library are not enabled
+// automatically. The functions predefined by the text/template package (e.g. "and" and "printf") are always allowed
+// unless explicitly disabled. Custom functions are not affected.
+//
+// - Clock is the clock used by the time-based template functions such as "now", "date", and "age". This includes the
+// Sprig date functions (e.g. "dateInZone" and "ago") when they are enabled. This defaults to the system clock. Set
+// this to a fake clock (e.g. from k8s.io/utils/clock/testing) to get deterministic output in tests.
+//
+// - DisabledFunctions is a slice of template function names that should be disabled. This takes precedence over
+// AllowedFunctions. Using a disabled function in a template results in an ErrFunctionDisabled error.
+//
+// - DiscoveryClient is the client used by the "serverVersion" template function when the TemplateResolver is created
+// with NewResolverWithDynamicWatcher. If it's not set, "serverVersion" returns an error in that case. The other
+// constructors ignore this since they already have a discovery client.
 //
 // - StartDelim customizes the start delimiter used to distinguish a template action. This defaults
 // to "{{". If StopDelim is set, this must also be set.
@@ -85,12 +160,18 @@ var (
 // and cache entries are cleaned up. The manual control is done with the StartQueryBatch and EndQueryBatch methods.
 // This has no effect if caching is not enabled.
 type Config struct {
-	AdditionalIndentation      uint32
-	DisabledFunctions          []string
-	StartDelim                 string
-	StopDelim                  string
-	MissingAPIResourceCacheTTL time.Duration
-	SkipBatchManagement        bool
+	AdditionalIndentation               uint32
+	AdditionalSprigCategories           []SprigCategory
+	AdditionalSprigFunctions            []string
+	AllowNonDeterministicSprigFunctions bool
+	AllowedFunctions                    []string
+	Clock                               clock.PassiveClock
+	DisabledFunctions                   []string
+	DiscoveryClient                     discovery.ServerVersionInterface
+	StartDelim                          string
+	StopDelim                           string
+	MissingAPIResourceCacheTTL          time.Duration
+	SkipBatchManagement                 bool
 }
 
 // ResolveOptions is a struct containing configuration for calling ResolveTemplate.
@@ -99,39 +180,97 @@ type Config struct {
 // query API. This is useful if you want to add information about a Kubernetes object in the context and be notified
 // when the object changes.
 //
+// - Clock overrides Config.Clock for this ResolveTemplate call.
+//
 // - ClusterScopedAllowList is a list of cluster-scoped object identifiers (group, kind, name) which
-// are allowed to be used in "lookup" calls even when LookupNamespace is set. A wildcard value `*`
-// may be used in any or all of the fields. The default behavior when LookupNamespace is set is to
-// deny all cluster-scoped lookups.
+// are allowed to be used in "lookup" calls even when lookups are restricted to namespaces (e.g. LookupNamespace is
+// set). See ClusterScopedObjectIdentifier for the supported patterns and label selectors. The default behavior when
+// lookups are restricted to namespaces is to deny all cluster-scoped lookups.
 //
 // - CustomFunctions is an optional map of custom functions available during template resolution.
 //
+// - CustomFunctionFactories is an optional map of custom function factories which are called at the start of each
+// ResolveTemplate call to create custom functions with access to the Resolution (e.g. to perform cached and restricted
+// lookups). A factory takes precedence over a function of the same name in CustomFunctions.
+//
+// - AllowedFunctions further restricts the default template functions allowed in this ResolveTemplate call. See
+// Config.AllowedFunctions for the semantics. This can't enable a function disabled by the Config.
+//
+// - DisabledFunctions disables additional template functions in this ResolveTemplate call. This is useful when the
+// allowed functions depend on the object that includes the templates, such as on a multi-tenant hub.
+//
 // - EncryptionConfig is the configuration for template encryption/decryption functionality.
 //
 // - InputIsYAML can be set to true to indicate that the input to the template is already in YAML format and thus does
 // not need to be converted from JSON to YAML before template processing occurs. This should be set to true when
 // passing raw YAML directly to the template resolver.
 //
+// - LookupIgnoreForbidden causes a "lookup" call that fails because the user is forbidden from accessing the resource
+// to return an empty result instead of failing the template resolution. A warning is added to the TemplateResult.
+//
+// - LookupIgnoreMissingAPIResource causes a "lookup" call of an API resource that isn't installed on the Kubernetes
+// API server (e.g. the CRD of an optional operator) to return an empty result instead of an ErrMissingAPIResource
+// error. A warning is added to the TemplateResult.
+//
 // - LookupNamespace is the namespace to restrict "lookup" template functions (e.g. fromConfigMap)
 // to. If this is not set (i.e. an empty string), then all namespaces can be used.
 //
+// - LookupNamespaces is a list of additional namespaces to allow "lookup" template functions to use. It can be combined
+// with LookupNamespace and LookupNamespaceSelector, in which case a namespace allowed by any of them can be used.
+//
+// - LookupNamespaceSelector is a label selector (e.g. "tenant=a") of the namespaces to allow "lookup" template
+// functions to use. The Namespace objects are retrieved to evaluate the selector, so when caching is enabled, a change
+// to their labels triggers a reconcile of the watcher.
+//
+// When lookups are restricted to exactly one namespace through LookupNamespace or LookupNamespaces and
+// LookupNamespaceSelector is not set, a lookup that doesn't specify a namespace defaults to that namespace. Otherwise,
+// the namespace must be specified when looking up namespaced resources.
+//
+// - StrictMode causes mistakes in the template to fail the resolution instead of silently rendering an empty or
+// incorrect value. Accessing a missing map key (e.g. in the context) is an error instead of rendering "<no value>", a
+// "lookup" of a named object that doesn't exist returns an ErrObjectNotFound error with the object identifier, the
+// "base64dec", "atoi", and "toBool" functions return an error when the input can't be parsed, and the "jsonpath"
+// function returns an error when a key is missing.
+//
 // - Watcher is the Kubernetes object that includes the templates. This is only used when caching is enabled.
 type ResolveOptions struct {
+	AllowedFunctions    []string
 	ContextTransformers []func(
 		queryAPI CachingQueryAPI, context interface{},
 	) (transformedContext interface{}, err error)
-	ClusterScopedAllowList []ClusterScopedObjectIdentifier
-	CustomFunctions        template.FuncMap
+	Clock                   clock.PassiveClock
+	ClusterScopedAllowList  []ClusterScopedObjectIdentifier
+	CustomFunctions         template.FuncMap
+	CustomFunctionFactories map[string]CustomFunctionFactory
+	DisabledFunctions       []string
 	EncryptionConfig
-	InputIsYAML     bool
-	LookupNamespace string
-	Watcher         *client.ObjectIdentifier
+	InputIsYAML                    bool
+	LookupIgnoreForbidden          bool
+	LookupIgnoreMissingAPIResource bool
+	LookupNamespace                string
+	LookupNamespaces               []string
+	LookupNamespaceSelector        string
+	StrictMode                     bool
+	Watcher                        *client.ObjectIdentifier
 }
 
+// ClusterScopedObjectIdentifier is an entry in ResolveOptions.ClusterScopedAllowList.
+//
+// - Group, Kind, and Name are glob patterns (e.g. "team-a-*") matched against the lookup. A wildcard value `*` matches
+// any value. Note that a lookup that lists objects has an empty name, so the Name must match an empty string (e.g. `*`)
+// to allow it.
+//
+// - NameRegex is an optional regular expression that must match the entire name. When set, Name is ignored.
+//
+// - LabelSelector is an optional label selector (e.g. "team=a") that the looked up objects must match. A lookup of a
+// single object that doesn't match returns a ClusterScopedLookupRestrictedError, and a lookup that lists objects only
+// returns the matching objects.
 type ClusterScopedObjectIdentifier struct {
-	Group string
-	Kind  string
-	Name  string
+	Group         string
+	Kind          string
+	Name          string
+	NameRegex     string
+	LabelSelector string
 }
 
 // EncryptionConfig is a struct containing configuration for template encryption/decryption functionality.
@@ -177,12 +316,26 @@ type TemplateResolver struct {
 	// If caching is disabled, this will act as a temporary cache for objects during the execution of the
 	// ResolveTemplate call.
 	tempCallCache client.ObjectCache
+	// If caching is disabled, this is a temporary cache of the list queries with field selectors during the execution
+	// of the ResolveTemplate call. The keys are fieldSelectorCacheKey values.
+	fieldSelectorCache sync.Map
+	// Used to get the Kubernetes version of the API server. This is Config.DiscoveryClient when instantiated with
+	// NewResolverWithDynamicWatcher.
+	discoveryClient discovery.ServerVersionInterface
+	// Caches the Kubernetes version of the API server for serverVersionCacheTTL to avoid a request on every
+	// "serverVersion" call.
+	serverVersionLock    sync.Mutex
+	serverVersionCache   string
+	serverVersionExpires time.Time
 }
 
 type TemplateResult struct {
 	ResolvedJSON []byte
 	// HasSensitiveData is true if a template references a secret or decrypts an encrypted value.
 	HasSensitiveData bool
+	// Warnings are non-fatal issues encountered during template resolution, such as a "lookup" that was treated as an
+	// empty result due to ResolveOptions.LookupIgnoreForbidden or ResolveOptions.LookupIgnoreMissingAPIResource.
+	Warnings []string
 }
 
 // NewResolver creates a new (non-caching) TemplateResolver instance, which is the API for processing templates.
@@ -215,6 +368,10 @@ func NewResolverWithClients(
 		return nil, fmt.Errorf("the configurations StartDelim and StopDelim cannot be set independently")
 	}
 
+	if err := validateSprigConfig(config); err != nil {
+		return nil, err
+	}
+
 	// It's only required to check config.StartDelim since it's invalid to set these independently
 	if config.StartDelim == "" {
 		config.StartDelim = defaultStartDelim
@@ -233,10 +390,11 @@ func NewResolverWithClients(
 	)
 
 	return &TemplateResolver{
-		config:         config,
-		dynamicClient:  dynamicClient,
-		dynamicWatcher: nil,
-		tempCallCache:  tempCallCache,
+		config:          config,
+		dynamicClient:   dynamicClient,
+		dynamicWatcher:  nil,
+		tempCallCache:   tempCallCache,
+		discoveryClient: discoveryClient,
 	}, nil
 }
 
@@ -291,7 +449,8 @@ func NewResolverWithCaching(
 
 // NewResolverWithDynamicWatcher creates a new caching TemplateResolver instance, using the provided dependency-watcher.
 // The caller is responsible for managing the given DynamicWatcher, including starting and stopping it. The caller must
-// start a query batch on the DynamicWatcher for the "watcher" object before calling ResolveTemplate.
+// start a query batch on the DynamicWatcher for the "watcher" object before calling ResolveTemplate. Set
+// Config.DiscoveryClient to use the "serverVersion" template function.
 //
 // - dynWatcher is an already running DynamicWatcher from kubernetes-dependency-watches.
 //
@@ -301,6 +460,10 @@ func NewResolverWithDynamicWatcher(dynWatcher client.DynamicWatcher, config Conf
 		return nil, fmt.Errorf("the configurations StartDelim and StopDelim cannot be set independently")
 	}
 
+	if err := validateSprigConfig(config); err != nil {
+		return nil, err
+	}
+
 	// It's only required to check config.StartDelim since it's invalid to set these independently
 	if config.StartDelim == "" {
 		config.StartDelim = defaultStartDelim
@@ -308,10 +471,11 @@ func NewResolverWithDynamicWatcher(dynWatcher client.DynamicWatcher, config Conf
 	}
 
 	return &TemplateResolver{
-		config:         config,
-		dynamicClient:  nil,
-		dynamicWatcher: dynWatcher,
-		tempCallCache:  nil,
+		config:          config,
+		dynamicClient:   nil,
+		dynamicWatcher:  dynWatcher,
+		tempCallCache:   nil,
+		discoveryClient: config.DiscoveryClient,
 	}, nil
 }
 
@@ -515,7 +679,9 @@ func (t *TemplateResolver) EndQueryBatch(watcher client.ObjectIdentifier) error
 //
 // ResolveTemplate will process any template strings in the map and return the processed map. The
 // ErrMissingAPIResource is returned when one or more "lookup" calls referenced an API resource
-// which isn't installed on the Kubernetes API server.
+// which isn't installed on the Kubernetes API server. A failure in a stage of the resolution is returned as a
+// *ResolutionError and a failed query to the Kubernetes API is wrapped in a *LookupError, so the cause can be
+// determined with errors.Is and errors.As.
 //
 // The input options contains options for template resolution. The options.Watcher field is an ObjectIdentifier that is
 // used in caching mode and the controller-runtime integration. Set this to nil when not in caching mode. When in
@@ -560,60 +726,24 @@ func (t *TemplateResolver) ResolveTemplate(
 		return resolvedResult, err
 	}
 
-	// Build Map of supported template functions
-	funcMap := template.FuncMap{
-		"copyConfigMapData":      t.copyConfigMapDataHelper(options),
-		"copySecretData":         t.copySecretDataHelper(options, &resolvedResult),
-		"fromSecret":             t.fromSecretHelper(options, &resolvedResult),
-		"fromConfigMap":          t.fromConfigMapHelper(options),
-		"fromClusterClaim":       t.fromClusterClaimHelper(options),
-		"getNodesWithExactRoles": t.getNodesWithExactRolesHelper(options, &resolvedResult),
-		"hasNodesWithExactRoles": t.hasNodesWithExactRolesHelper(options),
-		"lookup":                 t.lookupHelper(options, &resolvedResult),
-		"base64enc":              base64encode,
-		"base64dec":              base64decode,
-		"b64enc":                 base64encode, // Link the Sprig name to our function
-		"b64dec":                 base64decode, // Link the Sprig name to our function
-		"autoindent":             autoindent,
-		"indent":                 t.indent,
-		"atoi":                   atoi,
-		"toInt":                  toInt,
-		"toBool":                 toBool,
-		"toLiteral":              toLiteral,
-	}
-
-	// Add all the functions from sprig we will support
-	for _, fname := range exportedSprigFunctions {
-		funcMap[fname] = getSprigFunc(fname)
-	}
-
-	if options.EncryptionEnabled {
-		funcMap["fromSecret"] = t.fromSecretProtectedHelper(options, &resolvedResult)
-		funcMap["protect"] = t.protectHelper(options)
-		funcMap["copySecretData"] = t.copySecretDataProtectedHelper(options, &resolvedResult)
-	} else {
-		// In other encryption modes, return a readable error if the protect template function is accidentally used.
-		funcMap["protect"] = func(s string) (string, error) { return "", ErrProtectNotEnabled }
-	}
-
-	for _, funcName := range t.config.DisabledFunctions {
-		delete(funcMap, funcName)
-	}
-
-	for customFuncName, customFunc := range options.CustomFunctions {
-		funcMap[customFuncName] = customFunc
-	}
+	funcMap := t.getFuncMap(options, &resolvedResult, &ctx)
 
 	// create template processor and Initialize function map
 	tmpl := template.New("tmpl").Delims(t.config.StartDelim, t.config.StopDelim).Funcs(funcMap)
 
+	if options.StrictMode {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
 	// convert the JSON to YAML if necessary
 	var templateStr string
 
 	if !options.InputIsYAML {
 		templateYAMLBytes, err := JSONToYAML(tmplRaw)
 		if err != nil {
-			return resolvedResult, fmt.Errorf("failed to convert the policy template to YAML: %w", err)
+			return resolvedResult, newResolutionError(
+				ErrInputConversionFailed, err, "failed to convert the policy template to YAML",
+			)
 		}
 
 		templateStr = string(templateYAMLBytes)
@@ -626,7 +756,7 @@ func (t *TemplateResolver) ResolveTemplate(
 	if options.DecryptionEnabled {
 		templateStr, err = t.processEncryptedStrs(options, &resolvedResult, templateStr)
 		if err != nil {
-			return resolvedResult, err
+			return resolvedResult, newResolutionError(ErrDecryptionFailed, err, "")
 		}
 	}
 
@@ -646,7 +776,9 @@ func (t *TemplateResolver) ResolveTemplate(
 			"error parsing template string %v,\n template str %v,\n error: %v", tmplRawStr, templateStr, err,
 		)
 
-		return resolvedResult, fmt.Errorf("failed to parse the template JSON string %v: %w", tmplRawStr, err)
+		return resolvedResult, newResolutionError(
+			ErrParseFailed, err, "failed to parse the template JSON string %v", tmplRawStr,
+		)
 	}
 
 	var buf bytes.Buffer
@@ -654,6 +786,7 @@ func (t *TemplateResolver) ResolveTemplate(
 	// If the dynamic watcher caching style is disabled, clear the cache after resolving the template.
 	if t.tempCallCache != nil {
 		defer t.tempCallCache.Clear()
+		defer t.clearFieldSelectorCache()
 	}
 
 	if t.dynamicWatcher != nil {
@@ -682,12 +815,16 @@ func (t *TemplateResolver) ResolveTemplate(
 		for i, contextTransformer := range options.ContextTransformers {
 			var err error
 
-			queryObj := cachingQueryAPI{dynamicWatcher: t.dynamicWatcher, watcher: *options.Watcher}
+			queryObj := cachingQueryAPI{resolver: t, options: options, templateResult: &resolvedResult}
 
 			ctx, err = contextTransformer(&queryObj, context)
 			if err != nil {
-				return resolvedResult, fmt.Errorf(
-					"%w at options.ContextTransformers[%d]: %w", ErrContextTransformerFailed, i, err,
+				return resolvedResult, newResolutionError(
+					ErrContextTransformerFailed,
+					err,
+					"%v at options.ContextTransformers[%d]",
+					ErrContextTransformerFailed,
+					i,
 				)
 			}
 		}
@@ -695,10 +832,20 @@ func (t *TemplateResolver) ResolveTemplate(
 
 	err = tmpl.Execute(&buf, ctx)
 	if err != nil {
+		// A failure defined by the template author is wrapped without the template text so that the caller can report
+		// the message. The *TemplateFailError can be retrieved with errors.As.
+		if failErr := asTemplateFailError(err); failErr != nil {
+			klog.V(2).Infof("the template called the fail function: %v", failErr)
+
+			return resolvedResult, newResolutionError(ErrExecutionFailed, failErr, "")
+		}
+
 		tmplRawStr := string(tmplRaw)
 		klog.Errorf("error resolving the template %v,\n template str %v,\n error: %v", tmplRawStr, templateStr, err)
 
-		return resolvedResult, fmt.Errorf("failed to resolve the template %v: %w", tmplRawStr, err)
+		return resolvedResult, newResolutionError(
+			ErrExecutionFailed, err, "failed to resolve the template %v", tmplRawStr,
+		)
 	}
 
 	resolvedTemplateStr := buf.String()
@@ -707,7 +854,9 @@ func (t *TemplateResolver) ResolveTemplate(
 
 	resolvedTemplateBytes, err := yamlToJSON(buf.Bytes())
 	if err != nil {
-		return resolvedResult, fmt.Errorf("failed to convert the resolved template to JSON: %w", err)
+		return resolvedResult, newResolutionError(
+			ErrOutputConversionFailed, err, "failed to convert the resolved template to JSON",
+		)
 	}
 
 	resolvedResult.ResolvedJSON = resolvedTemplateBytes
@@ -715,6 +864,169 @@ func (t *TemplateResolver) ResolveTemplate(
 	return resolvedResult, nil
 }
 
+// getFuncMap builds the map of template functions available during a single ResolveTemplate call. The templateCtx
+// pointer is used by functions such as tpl which need the final context after any context transformers are applied.
+func (t *TemplateResolver) getFuncMap(
+	options *ResolveOptions, templateResult *TemplateResult, templateCtx *interface{},
+) template.FuncMap {
+	// Build Map of supported template functions
+	funcMap := template.FuncMap{
+		"copyConfigMapData":       t.copyConfigMapDataHelper(options),
+		"copySecretData":          t.copySecretDataHelper(options, templateResult),
+		"fromSecret":              t.fromSecretHelper(options, templateResult),
+		"fromConfigMap":           t.fromConfigMapHelper(options),
+		"fromClusterClaim":        t.fromClusterClaimHelper(options),
+		"getNodesWithExactRoles":  t.getNodesWithExactRolesHelper(options, templateResult),
+		"hasNodesWithExactRoles":  t.hasNodesWithExactRolesHelper(options),
+		"lookup":                  t.lookupHelper(options, templateResult),
+		"lookupAcrossNamespaces":  t.lookupAcrossNamespacesHelper(options, templateResult),
+		"lookupWithFieldSelector": t.lookupWithFieldSelectorHelper(options, templateResult),
+		"base64enc":               base64encode,
+		"base64dec":               base64decode,
+		"b64enc":                  base64encode, // Link the Sprig name to our function
+		"b64dec":                  base64decode, // Link the Sprig name to our function
+		"autoindent":              autoindent,
+		"indent":                  t.indent,
+		"atoi":                    atoi,
+		"toInt":                   toInt,
+		"toBool":                  toBool,
+		"toLiteral":               toLiteral,
+		"fail":                    fail,
+		"now":                     t.nowHelper(options),
+		"date":                    t.dateHelper(options),
+		"timestamp":               timestamp,
+		"age":                     t.ageHelper(options),
+		"olderThan":               t.olderThanHelper(options),
+		"parseDuration":           parseDuration,
+		"jsonpath":                jsonPath,
+		"filterItems":             filterItems,
+		"filterItemsByJSONPath":   filterItemsByJSONPath,
+		"sortItems":               sortItems,
+		"groupItemsByLabel":       groupItemsByLabel,
+		"pluckItems":              pluckItems,
+		"dedupeItems":             dedupeItems,
+
+		// Status condition and readiness functions
+		"conditionStatus":             conditionStatus,
+		"conditionReason":             conditionReason,
+		"conditionMessage":            conditionMessage,
+		"isReady":                     isReady,
+		"isObservedGenerationCurrent": isObservedGenerationCurrent,
+
+		// Resource quantity functions
+		"addQuantity":     addQuantity,
+		"subQuantity":     subQuantity,
+		"mulQuantity":     mulQuantity,
+		"compareQuantity": compareQuantity,
+		"formatQuantity":  formatQuantity,
+
+		// Node selection and aggregation functions
+		"getNodes":             t.getNodesHelper(options, templateResult),
+		"nodesWithTaint":       nodesWithTaint,
+		"nodesWithoutTaint":    nodesWithoutTaint,
+		"nodeCount":            nodeCount,
+		"nodeCapacity":         nodeCapacity,
+		"nodeAllocatable":      nodeAllocatable,
+		"nodeZones":            nodeZones,
+		"nodeRegions":          nodeRegions,
+		"nodeArchitectures":    nodeArchitectures,
+		"nodeOperatingSystems": nodeOperatingSystems,
+
+		// Certificate functions
+		"parseCertificate":           parseCertificate,
+		"certificateDaysUntilExpiry": t.certificateDaysUntilExpiryHelper(options),
+		"verifyCertificate":          t.verifyCertificateHelper(options),
+
+		// Network address and CIDR functions
+		"ipInCIDR":      ipInCIDR,
+		"cidrSubnets":   cidrSubnets,
+		"cidrHost":      cidrHost,
+		"cidrsOverlap":  cidrsOverlap,
+		"normalizeIP":   normalizeIP,
+		"normalizeCIDR": normalizeCIDR,
+
+		// Patch functions
+		"jsonPatch":           jsonPatch,
+		"mergePatch":          mergePatch,
+		"strategicMergePatch": strategicMergePatch,
+
+		// Owner reference functions
+		"lookupOwner":    t.lookupOwnerHelper(options, templateResult),
+		"lookupChildren": t.lookupChildrenHelper(options, templateResult),
+
+		// API capability functions
+		"hasAPIResource":          t.hasAPIResource,
+		"isNamespacedAPIResource": t.isNamespacedAPIResource,
+		"serverVersion":           t.serverVersionHelper(options),
+	}
+
+	if options.StrictMode {
+		funcMap["base64dec"] = base64decodeStrict
+		funcMap["b64dec"] = base64decodeStrict
+		funcMap["atoi"] = atoiStrict
+		funcMap["toBool"] = toBoolStrict
+		funcMap["jsonpath"] = jsonPathStrict
+	}
+
+	funcMap["tpl"] = t.tplHelper(options, funcMap, templateCtx)
+
+	// Add all the functions from sprig we will support
+	for _, fname := range t.sprigFunctionNames() {
+		// Functions provided by this library (e.g. "date" and "now" with the configured clock) take precedence
+		if _, ok := builtinFunctionMetadata[fname]; ok {
+			continue
+		}
+
+		funcMap[fname] = getSprigFunc(fname)
+	}
+
+	for fname, clockFunc := range t.clockSprigFunctions(options) {
+		if _, ok := funcMap[fname]; ok {
+			funcMap[fname] = clockFunc
+		}
+	}
+
+	if options.EncryptionEnabled {
+		funcMap["fromSecret"] = t.fromSecretProtectedHelper(options, templateResult)
+		funcMap["protect"] = t.protectHelper(options)
+		funcMap["copySecretData"] = t.copySecretDataProtectedHelper(options, templateResult)
+	} else {
+		// In other encryption modes, return a readable error if the protect template function is accidentally used.
+		funcMap["protect"] = func(s string) (string, error) { return "", ErrProtectNotEnabled }
+	}
+
+	// Disabled functions are replaced instead of removed so that using them results in a descriptive error rather than
+	// a "function not defined" parse error.
+	for funcName := range funcMap {
+		if reason := t.disabledFunctionReason(options, funcName); reason != "" {
+			funcMap[funcName] = disabledFunction(funcName, reason)
+		}
+	}
+
+	// The functions predefined by text/template aren't in the function map, so they can only be explicitly disabled
+	for _, funcName := range slices.Concat(t.config.DisabledFunctions, options.DisabledFunctions) {
+		if _, ok := funcMap[funcName]; !ok {
+			funcMap[funcName] = disabledFunction(funcName, t.disabledFunctionReason(options, funcName))
+		}
+	}
+
+	for customFuncName, customFunc := range options.CustomFunctions {
+		funcMap[customFuncName] = customFunc
+	}
+
+	if len(options.CustomFunctionFactories) != 0 {
+		res := &resolution{
+			cachingQueryAPI{resolver: t, options: options, templateResult: templateResult},
+		}
+
+		for customFuncName, factory := range options.CustomFunctionFactories {
+			funcMap[customFuncName] = factory(res)
+		}
+	}
+
+	return funcMap
+}
+
 // UncacheWatcher will clear the watcher from the cache and remove all associated API watches.
 func (t *TemplateResolver) UncacheWatcher(watcher client.ObjectIdentifier) error {
 	if t.dynamicWatcher == nil {
@@ -887,6 +1199,27 @@ func toBool(a string) bool {
 	return b
 }
 
+// atoiStrict is the atoi template function used in strict mode, which returns an error if the input isn't an integer.
+func atoiStrict(a string) (int, error) {
+	i, err := strconv.Atoi(a)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return i, nil
+}
+
+// toBoolStrict is the toBool template function used in strict mode, which returns an error if the input isn't a
+// boolean.
+func toBoolStrict(a string) (bool, error) {
+	b, err := strconv.ParseBool(a)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return b, nil
+}
+
 // toLiteral just returns the input string as it is, however, this template function will be used to detect when
 // to remove quotes around the template string after the template is processed.
 func toLiteral(a string) (string, error) {
@@ -897,9 +1230,12 @@ func toLiteral(a string) (string, error) {
 	return a, nil
 }
 
-// CachingQueryAPI is a limited query API that will cache results. This is used with ContextTransformers.
+// CachingQueryAPI is a limited query API that will cache results. This is used with ContextTransformers. The queries
+// are subject to the same restrictions as the "lookup" template function (e.g. LookupNamespace and
+// ClusterScopedAllowList), a query of an API resource that isn't installed returns an ErrMissingAPIResource error, and
+// the namespace is ignored for cluster-scoped resources. Querying a Secret sets HasSensitiveData on the TemplateResult.
 type CachingQueryAPI interface {
-	// Get will add an additional watch and return the watched object.
+	// Get will add an additional watch and return the watched object. A nil object is returned if it's not found.
 	Get(
 		gvk schema.GroupVersionKind, namespace string, name string,
 	) (*unstructured.Unstructured, error)
@@ -909,19 +1245,67 @@ type CachingQueryAPI interface {
 	) ([]unstructured.Unstructured, error)
 }
 
+// LabelSelectorQueryAPI is implemented by the CachingQueryAPI passed to ContextTransformers. It's a separate interface
+// so that existing implementations of CachingQueryAPI aren't broken, so use a type assertion to access it.
+type LabelSelectorQueryAPI interface {
+	// ListWithLabelSelector is like List but accepts label selector strings (e.g. "env=prod") like the "lookup"
+	// template function. Multiple selectors are combined.
+	ListWithLabelSelector(
+		gvk schema.GroupVersionKind, namespace string, labelSelector ...string,
+	) ([]unstructured.Unstructured, error)
+}
+
 type cachingQueryAPI struct {
-	dynamicWatcher client.DynamicWatcher
-	watcher        client.ObjectIdentifier
+	resolver       *TemplateResolver
+	options        *ResolveOptions
+	templateResult *TemplateResult
 }
 
 func (c *cachingQueryAPI) Get(
 	gvk schema.GroupVersionKind, namespace string, name string,
 ) (*unstructured.Unstructured, error) {
-	return c.dynamicWatcher.Get(c.watcher, gvk, namespace, name)
+	result, err := c.resolver.getOrList(
+		c.options, c.templateResult, gvk.GroupVersion().String(), gvk.Kind, namespace, name,
+	)
+	if err != nil {
+		// Match the behavior of the DynamicWatcher where a not found object is returned as nil
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return &unstructured.Unstructured{Object: result}, nil
 }
 
 func (c *cachingQueryAPI) List(
 	gvk schema.GroupVersionKind, namespace string, selector labels.Selector,
 ) ([]unstructured.Unstructured, error) {
-	return c.dynamicWatcher.List(c.watcher, gvk, namespace, selector)
+	selectorStr := ""
+	if selector != nil {
+		selectorStr = selector.String()
+	}
+
+	return c.ListWithLabelSelector(gvk, namespace, selectorStr)
+}
+
+func (c *cachingQueryAPI) ListWithLabelSelector(
+	gvk schema.GroupVersionKind, namespace string, labelSelector ...string,
+) ([]unstructured.Unstructured, error) {
+	result, err := c.resolver.getOrList(
+		c.options, c.templateResult, gvk.GroupVersion().String(), gvk.Kind, namespace, "", labelSelector...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resultList := unstructured.UnstructuredList{}
+	resultList.SetUnstructuredContent(result)
+
+	return resultList.Items, nil
 }
@@ -15,7 +15,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 )
 
-const testNs = "testns"
+const (
+	testNs    = "testns"
+	testTplNs = "testns-tpl"
+)
 
 var (
 	k8sConfig       *rest.Config
@@ -74,6 +77,18 @@ func setUp() {
 		panic(err.Error())
 	}
 
+	// separate namespace for the tpl configmap so that it doesn't affect the configmap list queries in testns
+	tplNs := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testTplNs,
+		},
+	}
+
+	_, err = k8sClient.CoreV1().Namespaces().Create(ctx, &tplNs, metav1.CreateOptions{})
+	if err != nil {
+		panic(err.Error())
+	}
+
 	// sample secret
 	secret := corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
@@ -165,6 +180,20 @@ func setUp() {
 		},
 	}
 
+	// sample configmap with a templated value to test the tpl function
+	configmaptpl := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testcm-tpl",
+		},
+		Data: map[string]string{
+			"snippet": `{{ .ClusterName }}-{{ fromConfigMap "testns" "testconfigmap" "cmkey1" }}`,
+		},
+	}
+
 	_, err = k8sClient.CoreV1().ConfigMaps(testNs).Create(ctx, &configmap, metav1.CreateOptions{})
 	if err != nil {
 		panic(err.Error())
@@ -185,6 +214,11 @@ func setUp() {
 		panic(err.Error())
 	}
 
+	_, err = k8sClient.CoreV1().ConfigMaps(testTplNs).Create(ctx, &configmaptpl, metav1.CreateOptions{})
+	if err != nil {
+		panic(err.Error())
+	}
+
 	// sample Nodes to test Infra node lookups
 	nodea1 := corev1.Node{
 		TypeMeta: metav1.TypeMeta{
@@ -753,11 +753,57 @@ func TestResolveTemplateErrors(t *testing.T) {
 			inputTmpl: `data: '{{ fromSecret "testns" "testsecret" "secretkey1" }}'`,
 			config:    Config{DisabledFunctions: []string{"fromSecret"}},
 			expectedErr: errors.New(
-				`failed to parse the template JSON string {"data":"{{ fromSecret \"testns\" ` +
-					`\"testsecret\" \"secretkey1\" }}"}: template: tmpl:1: function "fromSecret" ` +
-					`not defined`,
+				`failed to resolve the template {"data":"{{ fromSecret \"testns\" \"testsecret\" \"secretkey1\" }}"}: ` +
+					`template: tmpl:1:10: executing "tmpl" at <fromSecret "testns" "testsecret" "secretkey1">: ` +
+					`error calling fromSecret: the template function is disabled: fromSecret (disabled by the ` +
+					`resolver configuration)`,
 			),
 		},
+		"disabled_predefined": {
+			inputTmpl:   `test: '{{ printf "hello %s" "world" }}'`,
+			config:      Config{DisabledFunctions: []string{"printf"}},
+			expectedErr: ErrFunctionDisabled,
+		},
+		"disabled_in_options": {
+			inputTmpl:      `data: '{{ fromSecret "testns" "testsecret" "secretkey1" }}'`,
+			resolveOptions: ResolveOptions{DisabledFunctions: []string{"fromSecret"}},
+			expectedErr:    ErrFunctionDisabled,
+		},
+		"allowed_functions": {
+			inputTmpl:      `test: '{{ fromConfigMap "testns" "testconfigmap" "cmkey1" | upper | printf "%s!" }}'`,
+			config:         Config{AllowedFunctions: []string{"fromConfigMap", "upper"}},
+			expectedResult: "test: CMKEY1VAL!",
+		},
+		"not_allowed_function": {
+			inputTmpl: `data: '{{ fromSecret "testns" "testsecret" "secretkey1" }}'`,
+			config:    Config{AllowedFunctions: []string{"fromConfigMap"}},
+			expectedErr: errors.New(
+				`failed to resolve the template {"data":"{{ fromSecret \"testns\" \"testsecret\" \"secretkey1\" }}"}: ` +
+					`template: tmpl:1:10: executing "tmpl" at <fromSecret "testns" "testsecret" "secretkey1">: ` +
+					`error calling fromSecret: the template function is disabled: fromSecret (not in the allowed ` +
+					`functions of the resolver configuration)`,
+			),
+		},
+		"not_allowed_function_in_options": {
+			inputTmpl:      `test: '{{ fromConfigMap "testns" "testconfigmap" "cmkey1" | upper }}'`,
+			config:         Config{AllowedFunctions: []string{"fromConfigMap", "upper"}},
+			resolveOptions: ResolveOptions{AllowedFunctions: []string{"fromConfigMap", "lower"}},
+			expectedErr:    ErrFunctionDisabled,
+		},
+		"allowed_functions_in_options_cannot_enable": {
+			inputTmpl:      `test: '{{ "hello" | upper }}'`,
+			config:         Config{DisabledFunctions: []string{"upper"}},
+			resolveOptions: ResolveOptions{AllowedFunctions: []string{"upper"}},
+			expectedErr:    ErrFunctionDisabled,
+		},
+		"allowed_functions_custom_function": {
+			inputTmpl: `test: '{{ greet }}'`,
+			config:    Config{AllowedFunctions: []string{"upper"}},
+			resolveOptions: ResolveOptions{
+				CustomFunctions: template.FuncMap{"greet": func() string { return "hello" }},
+			},
+			expectedResult: "test: hello",
+		},
 		"missing_api_resource": {
 			inputTmpl:   `value: '{{ lookup "v1" "NotAResource" "namespace" "object" }}'`,
 			config:      Config{},
@@ -1180,6 +1226,367 @@ func TestToBool(t *testing.T) {
 	}
 }
 
+func TestResolutionErrors(t *testing.T) {
+	t.Parallel()
+
+	resolver, err := NewResolver(k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	decryptionConfig := EncryptionConfig{
+		AESKey:               bytes.Repeat([]byte{byte('A')}, 256/8),
+		DecryptionEnabled:    true,
+		InitializationVector: bytes.Repeat([]byte{byte('I')}, IVSize),
+	}
+
+	testcases := map[string]struct {
+		input         string
+		options       ResolveOptions
+		expectedStage error
+		expectedErr   error
+	}{
+		"input_conversion": {
+			input:         `{"value": `,
+			expectedStage: ErrInputConversionFailed,
+		},
+		"decryption": {
+			input:         `value: $ocm_encrypted:QUFBQUFBQUFBQUFBQUFBQQ==`,
+			options:       ResolveOptions{InputIsYAML: true, EncryptionConfig: decryptionConfig},
+			expectedStage: ErrDecryptionFailed,
+			expectedErr:   ErrInvalidPKCS7Padding,
+		},
+		"parse": {
+			input:         `value: '{{ notAFunction }}'`,
+			options:       ResolveOptions{InputIsYAML: true},
+			expectedStage: ErrParseFailed,
+		},
+		"execute": {
+			input:         `value: '{{ lookup "v1" "NotAResource" "testns" "name" }}'`,
+			options:       ResolveOptions{InputIsYAML: true},
+			expectedStage: ErrExecutionFailed,
+			expectedErr:   ErrMissingAPIResource,
+		},
+		"output_conversion": {
+			input:         `value: '{{ "[" | toLiteral }}'`,
+			options:       ResolveOptions{InputIsYAML: true},
+			expectedStage: ErrOutputConversionFailed,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := resolver.ResolveTemplate([]byte(test.input), nil, &test.options)
+
+			var resolutionErr *ResolutionError
+			if !errors.As(err, &resolutionErr) {
+				t.Fatalf("Expected a ResolutionError but got: %v", err)
+			}
+
+			if resolutionErr.Stage != test.expectedStage || !errors.Is(err, test.expectedStage) { //nolint:errorlint
+				t.Fatalf("Expected the stage %v but got: %v", test.expectedStage, resolutionErr.Stage)
+			}
+
+			if test.expectedErr != nil && !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestResolutionErrorsContextTransformer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	resolver, _, err := NewResolverWithCaching(ctx, k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	transformerErr := errors.New("the transformer is broken")
+
+	_, err = resolver.ResolveTemplate([]byte(`value: '{{ .Name }}'`), nil, &ResolveOptions{
+		ContextTransformers: []func(CachingQueryAPI, interface{}) (interface{}, error){
+			func(CachingQueryAPI, interface{}) (interface{}, error) { return nil, transformerErr },
+		},
+		InputIsYAML: true,
+		Watcher:     &client.ObjectIdentifier{Version: "v1", Kind: "ConfigMap", Namespace: "testns", Name: "watcher"},
+	})
+	if !errors.Is(err, ErrContextTransformerFailed) || !errors.Is(err, transformerErr) {
+		t.Fatalf("Expected the context transformer error but got: %v", err)
+	}
+
+	expectedMsg := "the context transformer failed at options.ContextTransformers[0]: the transformer is broken"
+	if err.Error() != expectedMsg {
+		t.Fatalf("Expected the error message %q but got %q", expectedMsg, err.Error())
+	}
+}
+
+func TestContextTransformerRestrictions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	// The subtests run in parallel after this function returns
+	t.Cleanup(cancelFunc)
+
+	resolver, _, err := NewResolverWithCaching(ctx, k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	configMapGVK := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	testcases := map[string]struct {
+		query          func(api CachingQueryAPI) (interface{}, error)
+		expectedResult string
+		expectedErr    error
+	}{
+		"restricted_namespace": {
+			query: func(api CachingQueryAPI) (interface{}, error) {
+				return api.Get(configMapGVK, "default", "testconfigmap")
+			},
+			expectedErr: ErrRestrictedNamespace,
+		},
+		"restricted_cluster_scoped": {
+			query: func(api CachingQueryAPI) (interface{}, error) {
+				return api.List(schema.GroupVersionKind{Version: "v1", Kind: "Node"}, "", nil)
+			},
+			expectedErr: ErrClusterScopedLookupRestricted,
+		},
+		"missing_api": {
+			query: func(api CachingQueryAPI) (interface{}, error) {
+				return api.Get(schema.GroupVersionKind{Version: "v1", Kind: "NotAResource"}, "testns", "name")
+			},
+			expectedErr: ErrMissingAPIResource,
+		},
+		"default_namespace": {
+			query: func(api CachingQueryAPI) (interface{}, error) {
+				obj, err := api.Get(configMapGVK, "", "testconfigmap")
+				if err != nil {
+					return nil, err
+				}
+
+				return obj.GetNamespace(), nil
+			},
+			expectedResult: "testns",
+		},
+		"not_found": {
+			query: func(api CachingQueryAPI) (interface{}, error) {
+				obj, err := api.Get(configMapGVK, "testns", "does-not-exist")
+
+				return obj == nil, err
+			},
+			expectedResult: "true",
+		},
+		"label_selector_strings": {
+			query: func(api CachingQueryAPI) (interface{}, error) {
+				labelSelectorAPI, ok := api.(LabelSelectorQueryAPI)
+				if !ok {
+					return nil, errors.New("the query API doesn't implement LabelSelectorQueryAPI")
+				}
+
+				objs, err := labelSelectorAPI.ListWithLabelSelector(configMapGVK, "testns", "app=test", "env in (a,b)")
+
+				return len(objs), err
+			},
+			expectedResult: "2",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := resolver.ResolveTemplate([]byte(`value: '{{ .Value }}'`), nil, &ResolveOptions{
+				ContextTransformers: []func(CachingQueryAPI, interface{}) (interface{}, error){
+					func(api CachingQueryAPI, _ interface{}) (interface{}, error) {
+						value, err := test.query(api)
+
+						return struct{ Value interface{} }{value}, err
+					},
+				},
+				InputIsYAML:     true,
+				LookupNamespace: "testns",
+				Watcher: &client.ObjectIdentifier{
+					Version: "v1", Kind: "ConfigMap", Namespace: "testns", Name: "watcher-" + testName,
+				},
+			})
+
+			if test.expectedErr != nil {
+				if !errors.Is(err, ErrContextTransformerFailed) || !errors.Is(err, test.expectedErr) {
+					t.Fatalf("Expected the error %v but got: %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expected := fmt.Sprintf(`{"value":"%s"}`, test.expectedResult)
+			if string(result.ResolvedJSON) != expected {
+				t.Fatalf("Expected %s but got: %s", expected, result.ResolvedJSON)
+			}
+		})
+	}
+}
+
+func TestContextTransformerClusterScopedNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	resolver, _, err := NewResolverWithCaching(ctx, k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	namespaceGVK := schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}
+
+	// The namespace is ignored for cluster-scoped resources, so both queries share a single watch
+	result, err := resolver.ResolveTemplate([]byte(`value: '{{ .Value }}'`), nil, &ResolveOptions{
+		ContextTransformers: []func(CachingQueryAPI, interface{}) (interface{}, error){
+			func(api CachingQueryAPI, _ interface{}) (interface{}, error) {
+				ns, err := api.Get(namespaceGVK, "default", "testns")
+				if err != nil || ns == nil {
+					return nil, fmt.Errorf("expected the testns Namespace but got %v: %w", ns, err)
+				}
+
+				if _, err := api.Get(namespaceGVK, "", "testns"); err != nil {
+					return nil, err
+				}
+
+				return struct{ Value string }{ns.GetName()}, nil
+			},
+		},
+		InputIsYAML: true,
+		Watcher: &client.ObjectIdentifier{
+			Version: "v1", Kind: "ConfigMap", Namespace: "testns", Name: "watcher-cluster-scoped-namespace",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(result.ResolvedJSON) != `{"value":"testns"}` {
+		t.Fatalf("Unexpected template: %s", result.ResolvedJSON)
+	}
+
+	if resolver.GetWatchCount() != 1 {
+		t.Fatalf("Expected a watch count of 1 but got: %d", resolver.GetWatchCount())
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	t.Parallel()
+
+	strict := ResolveOptions{StrictMode: true}
+
+	testcases := map[string]resolveTestCase{
+		"missing_key": {
+			inputTmpl:      `value: '{{ .Labels.missing }}'`,
+			ctx:            struct{ Labels map[string]string }{map[string]string{"hello": "world"}},
+			resolveOptions: strict,
+			expectedErr: errors.New(
+				`failed to resolve the template {"value":"{{ .Labels.missing }}"}: template: tmpl:1:18: executing ` +
+					`"tmpl" at <.Labels.missing>: map has no entry for key "missing"`,
+			),
+		},
+		"missing_key_not_strict": {
+			inputTmpl:      `value: '{{ .Labels.missing }}'`,
+			ctx:            struct{ Labels map[string]string }{map[string]string{"hello": "world"}},
+			expectedResult: "value: <no value>",
+		},
+		"missing_key_in_tpl": {
+			inputTmpl:      `value: '{{ tpl "{{ .Labels.missing }}" }}'`,
+			ctx:            struct{ Labels map[string]string }{map[string]string{"hello": "world"}},
+			resolveOptions: strict,
+			expectedErr: errors.New(
+				`failed to resolve the template {"value":"{{ tpl \"{{ .Labels.missing }}\" }}"}: template: ` +
+					`tmpl:1:11: executing "tmpl" at <tpl "{{ .Labels.missing }}">: error calling tpl: failed to ` +
+					`resolve the string passed to tpl: template: tpl:1:10: executing "tpl" at <.Labels.missing>: map ` +
+					`has no entry for key "missing"`,
+			),
+		},
+		"lookup_not_found": {
+			inputTmpl:      `value: '{{ (lookup "v1" "ConfigMap" "testns" "does-not-exist").data }}'`,
+			resolveOptions: strict,
+			expectedErr: errors.New(
+				`failed to resolve the template {"value":"{{ (lookup \"v1\" \"ConfigMap\" \"testns\" ` +
+					`\"does-not-exist\").data }}"}: template: tmpl:1:12: executing "tmpl" at <lookup "v1" ` +
+					`"ConfigMap" "testns" "does-not-exist">: error calling lookup: the object was not found: v1 ` +
+					`ConfigMap testns/does-not-exist`,
+			),
+		},
+		"lookup_not_found_default_namespace": {
+			inputTmpl:      `value: '{{ (lookup "v1" "ConfigMap" "" "does-not-exist").data }}'`,
+			resolveOptions: ResolveOptions{StrictMode: true, LookupNamespace: "testns"},
+			expectedErr: errors.New(
+				`failed to resolve the template {"value":"{{ (lookup \"v1\" \"ConfigMap\" \"\" ` +
+					`\"does-not-exist\").data }}"}: template: tmpl:1:12: executing "tmpl" at <lookup "v1" ` +
+					`"ConfigMap" "" "does-not-exist">: error calling lookup: the object was not found: v1 ` +
+					`ConfigMap testns/does-not-exist`,
+			),
+		},
+		"lookup_not_found_cached": {
+			inputTmpl: `value: '{{ (lookup "v1" "ConfigMap" "testns" "does-not-exist" | default "") }}` +
+				`{{ (lookup "v1" "ConfigMap" "testns" "does-not-exist").data }}'`,
+			resolveOptions: strict,
+			expectedErr:    ErrObjectNotFound,
+		},
+		"lookup_list_empty": {
+			inputTmpl:      `value: '{{ len (lookup "v1" "ConfigMap" "testns" "" "app=does-not-exist").items }}'`,
+			resolveOptions: strict,
+			expectedResult: `value: "0"`,
+		},
+		"base64dec": {
+			inputTmpl:      `value: '{{ "not base64!" | base64dec }}'`,
+			resolveOptions: strict,
+			expectedErr:    ErrInvalidInput,
+		},
+		"b64dec": {
+			inputTmpl:      `value: '{{ "VGVtcGxhdGVz" | b64dec }}'`,
+			resolveOptions: strict,
+			expectedResult: "value: Templates",
+		},
+		"atoi": {
+			inputTmpl:      `value: '{{ "six" | atoi }}'`,
+			resolveOptions: strict,
+			expectedErr:    ErrInvalidInput,
+		},
+		"toBool": {
+			inputTmpl:      `value: '{{ "blah" | toBool }}'`,
+			resolveOptions: strict,
+			expectedErr:    ErrInvalidInput,
+		},
+		"toBool_valid": {
+			inputTmpl:      `value: '{{ "TRUE" | toBool }}'`,
+			resolveOptions: strict,
+			expectedResult: "value: true",
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			doResolveTest(t, test)
+		})
+	}
+}
+
 func TestProcessForDataTypes(t *testing.T) {
 	t.Parallel()
 
@@ -1255,32 +1662,49 @@ func TestGetNamespace(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		configuredNamespace string
-		actualNamespace     string
-		returnedNamespace   string
-		expectedError       error
+		configuredNamespace  string
+		configuredNamespaces []string
+		actualNamespace      string
+		returnedNamespace    string
+		expectedError        error
 	}{
-		{"my-policies", "my-policies", "my-policies", nil},
-		{"", "prod-configs", "prod-configs", nil},
-		{"my-policies", "", "my-policies", nil},
+		{"my-policies", nil, "my-policies", "my-policies", nil},
+		{"", nil, "prod-configs", "prod-configs", nil},
+		{"my-policies", nil, "", "my-policies", nil},
 		{
 			"my-policies",
+			nil,
 			"prod-configs",
 			"",
 			errors.New("the namespace argument is restricted to my-policies"),
 		},
 		{
 			"policies",
+			nil,
 			"prod-configs",
 			"",
 			errors.New("the namespace argument is restricted to policies"),
 		},
+		{"", []string{"tenant-a", "tenant-b"}, "tenant-b", "tenant-b", nil},
+		{"tenant-a", []string{"tenant-b"}, "tenant-a", "tenant-a", nil},
+		{"", []string{"tenant-a"}, "", "tenant-a", nil},
+		{"", []string{"tenant-a", "tenant-b"}, "", "", nil},
+		{
+			"tenant-a",
+			[]string{"tenant-a", "tenant-b"},
+			"prod-configs",
+			"",
+			errors.New("the namespace argument is restricted to tenant-a, tenant-b"),
+		},
 	}
 
 	for _, test := range tests {
 		resolver, _ := NewResolver(k8sConfig, Config{})
 
-		ns, err := resolver.getNamespace(test.actualNamespace, test.configuredNamespace)
+		ns, err := resolver.getNamespace(
+			&ResolveOptions{LookupNamespace: test.configuredNamespace, LookupNamespaces: test.configuredNamespaces},
+			test.actualNamespace,
+		)
 
 		if err == nil || test.expectedError == nil {
 			if !(err == nil && test.expectedError == nil) {
@@ -0,0 +1,178 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/clock"
+)
+
+// getClock returns the clock used by the time-based template functions. The clock in the ResolveOptions takes
+// precedence over the clock in the Config. The real clock is used if neither is set.
+func (t *TemplateResolver) getClock(options *ResolveOptions) clock.PassiveClock {
+	if options.Clock != nil {
+		return options.Clock
+	}
+
+	if t.config.Clock != nil {
+		return t.config.Clock
+	}
+
+	return clock.RealClock{}
+}
+
+func (t *TemplateResolver) nowHelper(options *ResolveOptions) func() time.Time {
+	return func() time.Time {
+		return t.getClock(options).Now()
+	}
+}
+
+func (t *TemplateResolver) dateHelper(options *ResolveOptions) func(string, interface{}) string {
+	return func(format string, date interface{}) string {
+		return t.date(options, format, date)
+	}
+}
+
+// date formats the input date with the input layout. This matches the Sprig function of the same name, except that the
+// current time comes from the configured clock when the input date is not a time or a Unix timestamp.
+func (t *TemplateResolver) date(options *ResolveOptions, format string, date interface{}) string {
+	return t.dateInZone(options, format, date, "Local")
+}
+
+// clockSprigFunctions returns replacements for the Sprig date functions that read the current time when the input date
+// is not a time or a Unix timestamp, so that they use the configured clock instead. The replacements are only used when
+// the Sprig functions are enabled (e.g. with the "date" Sprig category). Other Sprig date functions, such as
+// "dateModify", only operate on their input, so they follow the clock when given the output of "now".
+func (t *TemplateResolver) clockSprigFunctions(options *ResolveOptions) map[string]interface{} {
+	return map[string]interface{}{
+		"ago": func(date interface{}) string {
+			return t.getClock(options).Since(t.toTime(options, date)).Round(time.Second).String()
+		},
+		"dateInZone": func(format string, date interface{}, zone string) string {
+			return t.dateInZone(options, format, date, zone)
+		},
+		"htmlDate": func(date interface{}) string {
+			return t.dateInZone(options, "2006-01-02", date, "Local")
+		},
+		"htmlDateInZone": func(date interface{}, zone string) string {
+			return t.dateInZone(options, "2006-01-02", date, zone)
+		},
+	}
+}
+
+// dateInZone formats the input date with the input layout in the input time zone like the Sprig function of the same
+// name. The UTC time zone is used if the time zone is invalid.
+func (t *TemplateResolver) dateInZone(options *ResolveOptions, format string, date interface{}, zone string) string {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	return t.toTime(options, date).In(loc).Format(format)
+}
+
+// toTime converts the input time or Unix timestamp to a time like the Sprig date functions. Any other input results in
+// the current time according to the configured clock.
+func (t *TemplateResolver) toTime(options *ResolveOptions, date interface{}) time.Time {
+	var parsedDate time.Time
+
+	switch typedDate := date.(type) {
+	case time.Time:
+		parsedDate = typedDate
+	case *time.Time:
+		parsedDate = *typedDate
+	case int64:
+		parsedDate = time.Unix(typedDate, 0)
+	case int:
+		parsedDate = time.Unix(int64(typedDate), 0)
+	case int32:
+		parsedDate = time.Unix(int64(typedDate), 0)
+	default:
+		parsedDate = t.getClock(options).Now()
+	}
+
+	return parsedDate
+}
+
+// timestamp converts the input to a time. The input can be a time, an RFC 3339 timestamp as used in Kubernetes objects,
+// or a Kubernetes object (e.g. from the lookup function), in which case its creationTimestamp is used.
+func timestamp(value interface{}) (time.Time, error) {
+	switch typedValue := value.(type) {
+	case time.Time:
+		return typedValue, nil
+	case *time.Time:
+		return *typedValue, nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339, typedValue)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: the timestamp %s is not in the RFC 3339 format", ErrInvalidInput, value)
+		}
+
+		return parsed, nil
+	case map[string]interface{}:
+		obj := unstructured.Unstructured{Object: typedValue}
+
+		creationTimestamp, found, err := unstructured.NestedString(obj.Object, "metadata", "creationTimestamp")
+		if err != nil || !found || creationTimestamp == "" {
+			return time.Time{}, fmt.Errorf("%w: the object does not have a creationTimestamp", ErrInvalidInput)
+		}
+
+		return timestamp(creationTimestamp)
+	default:
+		return time.Time{}, fmt.Errorf(
+			"%w: expected a time, an RFC 3339 timestamp, or a Kubernetes object but got %T", ErrInvalidInput, value,
+		)
+	}
+}
+
+func (t *TemplateResolver) ageHelper(options *ResolveOptions) func(interface{}) (time.Duration, error) {
+	return func(value interface{}) (time.Duration, error) {
+		return t.age(options, value)
+	}
+}
+
+// age returns the duration since the input time, timestamp, or Kubernetes object creation according to the configured
+// clock.
+func (t *TemplateResolver) age(options *ResolveOptions, value interface{}) (time.Duration, error) {
+	parsed, err := timestamp(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.getClock(options).Since(parsed), nil
+}
+
+func (t *TemplateResolver) olderThanHelper(options *ResolveOptions) func(string, interface{}) (bool, error) {
+	return func(duration string, value interface{}) (bool, error) {
+		return t.olderThan(options, duration, value)
+	}
+}
+
+// olderThan returns true if the age of the input time, timestamp, or Kubernetes object is greater than the input
+// duration (e.g. "24h").
+func (t *TemplateResolver) olderThan(options *ResolveOptions, duration string, value interface{}) (bool, error) {
+	parsedDuration, err := parseDuration(duration)
+	if err != nil {
+		return false, err
+	}
+
+	age, err := t.age(options, value)
+	if err != nil {
+		return false, err
+	}
+
+	return age > parsedDuration, nil
+}
+
+// parseDuration parses the input duration string (e.g. "1h30m") like the time.ParseDuration function.
+func parseDuration(duration string) (time.Duration, error) {
+	parsed, err := time.ParseDuration(duration)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,163 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestTimeFunctions(t *testing.T) {
+	t.Parallel()
+
+	fakeNow := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(fakeNow)
+
+	testcases := map[string]resolveTestCase{
+		"now": {
+			inputTmpl:      `value: '{{ now | unixEpoch }}'`,
+			config:         Config{Clock: fakeClock, AdditionalSprigFunctions: []string{"unixEpoch"}},
+			expectedResult: `value: "1709294400"`,
+		},
+		"now_options_clock": {
+			inputTmpl: `value: '{{ now | unixEpoch }}'`,
+			config:    Config{Clock: fakeClock, AdditionalSprigFunctions: []string{"unixEpoch"}},
+			resolveOptions: ResolveOptions{
+				Clock: clocktesting.NewFakePassiveClock(fakeNow.Add(time.Hour)),
+			},
+			expectedResult: `value: "1709298000"`,
+		},
+		"date": {
+			inputTmpl:      `value: '{{ date "2006" now }}'`,
+			config:         Config{Clock: fakeClock},
+			expectedResult: `value: "2024"`,
+		},
+		"date_default": {
+			inputTmpl:      `value: '{{ date "2006" "not a date" }}'`,
+			config:         Config{Clock: fakeClock},
+			expectedResult: `value: "2024"`,
+		},
+		"sprig_date_in_zone": {
+			inputTmpl:      `value: '{{ dateInZone "2006-01-02T15:04" "not a date" "UTC" }}'`,
+			config:         Config{Clock: fakeClock, AdditionalSprigCategories: []SprigCategory{SprigCategoryDate}},
+			expectedResult: "value: 2024-03-01T12:00",
+		},
+		"sprig_html_date": {
+			inputTmpl:      `value: '{{ htmlDate "not a date" }} {{ htmlDateInZone "not a date" "Asia/Tokyo" }}'`,
+			config:         Config{Clock: fakeClock, AdditionalSprigCategories: []SprigCategory{SprigCategoryDate}},
+			expectedResult: "value: 2024-03-01 2024-03-01",
+		},
+		"sprig_date_modify": {
+			inputTmpl: `value: '{{ dateInZone "2006-01-02" (now | dateModify "-24h") "UTC" }}'`,
+			config: Config{
+				Clock:                    fakeClock,
+				AdditionalSprigFunctions: []string{"dateInZone", "dateModify"},
+			},
+			expectedResult: `value: "2024-02-29"`,
+		},
+		"sprig_ago": {
+			inputTmpl: `value: '{{ ago (timestamp "2024-03-01T10:30:00Z") }}'`,
+			config: Config{
+				Clock:                     fakeClock,
+				AdditionalSprigCategories: []SprigCategory{SprigCategoryDate},
+			},
+			expectedResult: "value: 1h30m0s",
+		},
+		"timestamp": {
+			inputTmpl:      `value: '{{ (timestamp "2024-02-29T12:00:00Z").Unix }}'`,
+			expectedResult: `value: "1709208000"`,
+		},
+		"timestamp_invalid": {
+			inputTmpl:   `value: '{{ timestamp "yesterday" }}'`,
+			expectedErr: ErrInvalidInput,
+		},
+		"age": {
+			inputTmpl:      `value: '{{ age "2024-02-29T12:00:00Z" }}'`,
+			config:         Config{Clock: fakeClock},
+			expectedResult: "value: 24h0m0s",
+		},
+		"age_object_without_timestamp": {
+			inputTmpl:   `value: '{{ dict "metadata" (dict "name" "test") | age }}'`,
+			config:      Config{Clock: fakeClock},
+			expectedErr: ErrInvalidInput,
+		},
+		"older_than": {
+			inputTmpl:      `value: '{{ olderThan "23h" "2024-02-29T12:00:00Z" }}'`,
+			config:         Config{Clock: fakeClock},
+			expectedResult: `value: "true"`,
+		},
+		"not_older_than": {
+			inputTmpl:      `value: '{{ "2024-02-29T12:00:00Z" | olderThan "25h" }}'`,
+			config:         Config{Clock: fakeClock},
+			expectedResult: `value: "false"`,
+		},
+		"older_than_invalid_duration": {
+			inputTmpl:   `value: '{{ olderThan "one day" "2024-02-29T12:00:00Z" }}'`,
+			config:      Config{Clock: fakeClock},
+			expectedErr: ErrInvalidInput,
+		},
+		"parse_duration": {
+			inputTmpl:      `value: '{{ (parseDuration "1h30m").Minutes }}'`,
+			expectedResult: `value: "90"`,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			doResolveTest(t, test)
+		})
+	}
+}
+
+func TestAgeOfLookup(t *testing.T) {
+	t.Parallel()
+
+	resolver, err := NewResolver(k8sConfig, Config{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	options := &ResolveOptions{}
+
+	configMap, err := resolver.lookup(options, nil, "v1", "ConfigMap", "testns", "testconfigmap")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	created, err := timestamp(configMap)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	options.Clock = clocktesting.NewFakePassiveClock(created.Add(48 * time.Hour))
+
+	age, err := resolver.age(options, configMap)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if age != 48*time.Hour {
+		t.Fatalf("Expected an age of 48h but got: %s", age)
+	}
+
+	olderThan, err := resolver.olderThan(options, "47h", configMap)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !olderThan {
+		t.Fatal("Expected the ConfigMap to be older than 47h")
+	}
+
+	_, err = timestamp(42)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected ErrInvalidInput but got: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"k8s.io/klog"
+)
+
+// tplMaxDepth is the maximum number of nested tpl calls allowed in a single template resolution. This prevents a
+// template that evaluates itself from recursing indefinitely.
+const tplMaxDepth = 10
+
+func (t *TemplateResolver) tplHelper(
+	options *ResolveOptions, funcMap template.FuncMap, templateCtx *interface{},
+) func(string) (string, error) {
+	depth := 0
+
+	return func(tmplStr string) (string, error) {
+		if depth >= tplMaxDepth {
+			return "", fmt.Errorf("%w of %d", ErrTplMaxDepthExceeded, tplMaxDepth)
+		}
+
+		depth++
+
+		defer func() { depth-- }()
+
+		return t.tpl(options, funcMap, *templateCtx, tmplStr)
+	}
+}
+
+// tpl evaluates the input string as a template using the same function map, delimiters, and context as the template
+// being resolved. This allows templated snippets to be stored as data (e.g. in a ConfigMap) and rendered by a policy.
+func (t *TemplateResolver) tpl(
+	options *ResolveOptions, funcMap template.FuncMap, templateCtx interface{}, tmplStr string,
+) (string, error) {
+	klog.V(2).Infof("tpl for: %v", tmplStr)
+
+	tmpl := template.New("tpl").Delims(t.config.StartDelim, t.config.StopDelim).Funcs(funcMap)
+
+	if options.StrictMode {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
+	tmpl, err := tmpl.Parse(tmplStr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the string passed to tpl: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = tmpl.Execute(&buf, templateCtx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the string passed to tpl: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,81 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package templates
+
+import (
+	"errors"
+	"testing"
+	"text/template"
+)
+
+func TestTpl(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]resolveTestCase{
+		"literal": {
+			inputTmpl:      `value: '{{ tpl "{{ \"hello\" | upper }}" }}'`,
+			expectedResult: "value: HELLO",
+		},
+		"context": {
+			inputTmpl:      `value: '{{ tpl "cluster-{{ .ClusterName }}" }}'`,
+			ctx:            struct{ ClusterName string }{"cluster1"},
+			expectedResult: "value: cluster-cluster1",
+		},
+		"from_configmap": {
+			inputTmpl:      `value: '{{ fromConfigMap "testns-tpl" "testcm-tpl" "snippet" | tpl }}'`,
+			ctx:            struct{ ClusterName string }{"cluster1"},
+			expectedResult: "value: cluster1-cmkey1Val",
+		},
+		"hub_delimiters": {
+			inputTmpl:      `value: '{{hub tpl "{{hub .ClusterName hub}} {{ .ClusterName }}" hub}}'`,
+			config:         Config{StartDelim: "{{hub", StopDelim: "hub}}"},
+			ctx:            struct{ ClusterName string }{"cluster1"},
+			expectedResult: "value: cluster1 {{ .ClusterName }}",
+		},
+		"nested": {
+			inputTmpl:      `value: '{{ tpl .Outer }}'`,
+			ctx:            struct{ Outer, Inner string }{`{{ tpl .Inner }}!`, `{{ "inner" }}`},
+			expectedResult: "value: inner!",
+		},
+		"custom_function": {
+			inputTmpl: `value: '{{ tpl "{{ greet }}" }}'`,
+			resolveOptions: ResolveOptions{
+				CustomFunctions: template.FuncMap{"greet": func() string { return "hello" }},
+			},
+			expectedResult: "value: hello",
+		},
+		"max_depth": {
+			inputTmpl:   `value: '{{ tpl .Loop }}'`,
+			ctx:         struct{ Loop string }{`{{ tpl .Loop }}`},
+			expectedErr: ErrTplMaxDepthExceeded,
+		},
+		"parse_error": {
+			inputTmpl: `value: '{{ tpl "{{ notAFunction }}" }}'`,
+			expectedErr: errors.New(
+				`failed to resolve the template {"value":"{{ tpl \"{{ notAFunction }}\" }}"}: template: tmpl:1:11: ` +
+					`executing "tmpl" at <tpl "{{ notAFunction }}">: error calling tpl: failed to parse the string ` +
+					`passed to tpl: template: tpl:1: function "notAFunction" not defined`,
+			),
+		},
+		"disabled": {
+			inputTmpl:   `value: '{{ tpl "{{ .ClusterName }}" }}'`,
+			config:      Config{DisabledFunctions: []string{"tpl"}},
+			expectedErr: ErrFunctionDisabled,
+		},
+		"disabled_inside_tpl": {
+			inputTmpl:   `value: '{{ tpl "{{ \"hello\" | upper }}" }}'`,
+			config:      Config{AllowedFunctions: []string{"tpl"}},
+			expectedErr: ErrFunctionDisabled,
+		},
+	}
+
+	for testName, test := range testcases {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			doResolveTest(t, test)
+		})
+	}
+}